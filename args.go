@@ -0,0 +1,284 @@
+package octoslash
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// argSpec describes how command line arguments are bound to the fields of an argument struct.
+//
+// Fields are bound using the following struct tags:
+//
+//   - arg:"name": positional argument (required unless the ",optional" option is set; slices consume the remaining arguments)
+//   - flag:"name": flag
+//   - short:"f": shorthand of a flag
+//   - help:"...": description of a flag or positional argument
+//
+// Supported field types are string, int, bool (flags only) and []string.
+// Tagged fields must be exported.
+type argSpec struct {
+	args  []argField
+	flags []flagField
+}
+
+type argField struct {
+	index    int
+	name     string
+	help     string
+	optional bool
+	variadic bool
+}
+
+type flagField struct {
+	index int
+	name  string
+	short string
+	help  string
+}
+
+func newArgSpec(t reflect.Type) (argSpec, error) {
+	var spec argSpec
+
+	if t.Kind() != reflect.Struct {
+		return spec, fmt.Errorf("arguments must be a struct, got %s", t)
+	}
+
+	optional := false
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		arg, isArg := field.Tag.Lookup("arg")
+		flag, isFlag := field.Tag.Lookup("flag")
+
+		switch {
+		case isArg && isFlag:
+			return spec, fmt.Errorf("field %s: both arg and flag tags are set", field.Name)
+
+		case (isArg || isFlag) && !field.IsExported():
+			// Unexported fields cannot be set through reflection
+			return spec, fmt.Errorf("field %s: arguments and flags must be exported fields", field.Name)
+
+		case isArg:
+			name, opts, _ := strings.Cut(arg, ",")
+			if name == "" {
+				name = kebabCase(field.Name)
+			}
+
+			a := argField{
+				index:    i,
+				name:     name,
+				help:     field.Tag.Get("help"),
+				optional: opts == "optional",
+			}
+
+			switch field.Type.Kind() {
+			case reflect.String, reflect.Int:
+
+			case reflect.Slice:
+				if field.Type.Elem().Kind() != reflect.String {
+					return spec, fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+				}
+
+				a.variadic = true
+
+			default:
+				return spec, fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+			}
+
+			if len(spec.args) > 0 && spec.args[len(spec.args)-1].variadic {
+				return spec, fmt.Errorf("field %s: arguments cannot follow a variadic argument", field.Name)
+			}
+
+			if !a.optional && optional {
+				return spec, fmt.Errorf("field %s: required argument after optional one", field.Name)
+			}
+
+			optional = optional || a.optional
+
+			spec.args = append(spec.args, a)
+
+		case isFlag:
+			if flag == "" {
+				flag = kebabCase(field.Name)
+			}
+
+			switch field.Type.Kind() {
+			case reflect.String, reflect.Int, reflect.Bool:
+
+			case reflect.Slice:
+				if field.Type.Elem().Kind() != reflect.String {
+					return spec, fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+				}
+
+			default:
+				return spec, fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+			}
+
+			spec.flags = append(spec.flags, flagField{
+				index: i,
+				name:  flag,
+				short: field.Tag.Get("short"),
+				help:  field.Tag.Get("help"),
+			})
+		}
+	}
+
+	return spec, nil
+}
+
+// use returns the usage line of a command (eg. "deploy <environment> [services...]").
+func (s argSpec) use(name string) string {
+	parts := []string{name}
+
+	for _, arg := range s.args {
+		usage := arg.name
+		if arg.variadic {
+			usage += "..."
+		}
+
+		if arg.optional {
+			usage = "[" + usage + "]"
+		} else {
+			usage = "<" + usage + ">"
+		}
+
+		parts = append(parts, usage)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// long returns the description of positional arguments.
+func (s argSpec) long() string {
+	var b strings.Builder
+
+	for _, arg := range s.args {
+		if arg.help == "" {
+			continue
+		}
+
+		if b.Len() == 0 {
+			b.WriteString("Arguments:\n")
+		}
+
+		fmt.Fprintf(&b, "  %s\t%s\n", arg.name, arg.help)
+	}
+
+	return b.String()
+}
+
+func (s argSpec) validator() cobra.PositionalArgs {
+	minArgs := 0
+	maxArgs := len(s.args)
+
+	for _, arg := range s.args {
+		if !arg.optional {
+			minArgs++
+		}
+
+		if arg.variadic {
+			maxArgs = -1
+		}
+	}
+
+	if maxArgs < 0 {
+		return cobra.MinimumNArgs(minArgs)
+	}
+
+	return cobra.RangeArgs(minArgs, maxArgs)
+}
+
+// bindFlags registers flags bound to the fields of v.
+func (s argSpec) bindFlags(flags *pflag.FlagSet, v reflect.Value) {
+	for _, flag := range s.flags {
+		field := v.Field(flag.index)
+
+		switch p := field.Addr().Interface().(type) {
+		case *string:
+			flags.StringVarP(p, flag.name, flag.short, "", flag.help)
+
+		case *int:
+			flags.IntVarP(p, flag.name, flag.short, 0, flag.help)
+
+		case *bool:
+			flags.BoolVarP(p, flag.name, flag.short, false, flag.help)
+
+		case *[]string:
+			flags.StringSliceVarP(p, flag.name, flag.short, nil, flag.help)
+		}
+	}
+}
+
+// bindArgs sets the positional arguments to the fields of v.
+func (s argSpec) bindArgs(args []string, v reflect.Value) error {
+	for i, arg := range s.args {
+		if i >= len(args) {
+			break
+		}
+
+		field := v.Field(arg.index)
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(args[i])
+
+		case reflect.Int:
+			n, err := strconv.Atoi(args[i])
+			if err != nil {
+				return fmt.Errorf("invalid value %q for argument %s: must be an integer", args[i], arg.name)
+			}
+
+			field.SetInt(int64(n))
+
+		case reflect.Slice:
+			field.Set(reflect.ValueOf(append([]string(nil), args[i:]...)))
+		}
+	}
+
+	return nil
+}
+
+// attributes returns the bound values by argument and flag name.
+func (s argSpec) attributes(v reflect.Value) map[string]any {
+	attrs := make(map[string]any, len(s.args)+len(s.flags))
+
+	for _, arg := range s.args {
+		attrs[arg.name] = v.Field(arg.index).Interface()
+	}
+
+	for _, flag := range s.flags {
+		attrs[flag.name] = v.Field(flag.index).Interface()
+	}
+
+	return attrs
+}
+
+// kebabCase converts a Go identifier to kebab case (eg. AddLabel -> add-label).
+func kebabCase(s string) string {
+	var b strings.Builder
+
+	runes := []rune(s)
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at a lower-to-upper transition or at the end of an acronym (eg. PRNumber -> pr-number)
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteRune('-')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,215 @@
+// Package audit records executed and denied commands.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+// Outcome describes how the execution of a command ended.
+type Outcome string
+
+const (
+	// OutcomeSucceeded is recorded when a command executed successfully.
+	OutcomeSucceeded Outcome = "succeeded"
+
+	// OutcomeFailed is recorded when a command was allowed, but failed.
+	OutcomeFailed Outcome = "failed"
+
+	// OutcomeDenied is recorded when a command was not authorized.
+	OutcomeDenied Outcome = "denied"
+
+	// OutcomeInvalid is recorded when a command was rejected during validation
+	// (eg. unknown commands or invalid arguments).
+	OutcomeInvalid Outcome = "invalid"
+)
+
+// Record is an audit log entry of a single command.
+type Record struct {
+	Time time.Time `json:"time"`
+
+	// Principal is the login of the user who issued the command.
+	Principal string `json:"principal"`
+
+	// Action is the authorization action of the command.
+	Action string `json:"action"`
+
+	// Resource is the issue or pull request the command was issued on (eg. owner/repo#12).
+	Resource string `json:"resource"`
+
+	// Args are the arguments of the command, including flags (eg. --reason=spam).
+	Args []string `json:"args"`
+
+	// Decision is the authorization decision (allow or deny).
+	//
+	// Empty if the command was rejected before authorization.
+	Decision string `json:"decision,omitempty"`
+
+	// Policies lists the IDs of the policies that determined the decision.
+	Policies []string `json:"policies,omitempty"`
+
+	Outcome Outcome `json:"outcome"`
+	Error   string  `json:"error,omitempty"`
+
+	Duration time.Duration `json:"duration"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	Record(ctx context.Context, record Record) error
+}
+
+// SinkFunc is an adapter to allow the use of ordinary functions as a [Sink].
+type SinkFunc func(ctx context.Context, record Record) error
+
+// Record calls fn(ctx, record).
+func (fn SinkFunc) Record(ctx context.Context, record Record) error {
+	return fn(ctx, record)
+}
+
+// Sinks sends records to every sink in the list.
+type Sinks []Sink
+
+// Record implements [Sink].
+func (s Sinks) Record(ctx context.Context, record Record) error {
+	var errs []error
+
+	for _, sink := range s {
+		if err := sink.Record(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Middleware records every command passing through it in sink.
+//
+// Failing to record a command does not fail the command: the error is logged instead.
+func Middleware(sink Sink, logger *slog.Logger) command.Middleware {
+	return func(next command.Handler) command.Handler {
+		return command.HandlerFunc(func(ctx context.Context, inv command.Invocation) error {
+			ctx, decision := command.WithDecisionRecorder(ctx)
+
+			start := time.Now()
+			err := next.Handle(ctx, inv)
+
+			record := newRecord(inv, decision(), err)
+			record.Time = start
+			record.Duration = time.Since(start)
+
+			if sinkErr := sink.Record(ctx, record); sinkErr != nil {
+				logger.Warn(
+					"recording audit log",
+					slog.String("action", inv.Action),
+					slog.Any("error", sinkErr),
+				)
+			}
+
+			return err
+		})
+	}
+}
+
+// ValidationMiddleware records every command rejected during validation in sink.
+//
+// Commands passing validation are not recorded: they are recorded by [Middleware] when executed.
+//
+// Failing to record a command does not fail the validation: the error is logged instead.
+func ValidationMiddleware(sink Sink, logger *slog.Logger) command.Middleware {
+	return func(next command.Handler) command.Handler {
+		return command.HandlerFunc(func(ctx context.Context, inv command.Invocation) error {
+			ctx, decision := command.WithDecisionRecorder(ctx)
+
+			start := time.Now()
+
+			err := next.Handle(ctx, inv)
+			if err == nil {
+				return nil
+			}
+
+			record := newRecord(inv, decision(), err)
+			record.Time = start
+			record.Duration = time.Since(start)
+
+			if record.Outcome == OutcomeFailed {
+				record.Outcome = OutcomeInvalid
+			}
+
+			if sinkErr := sink.Record(ctx, record); sinkErr != nil {
+				logger.Warn(
+					"recording audit log",
+					slog.String("action", inv.Action),
+					slog.Any("error", sinkErr),
+				)
+			}
+
+			return err
+		})
+	}
+}
+
+func newRecord(inv command.Invocation, decision *command.Decision, err error) Record {
+	record := Record{
+		Principal: inv.Event.GetComment().GetUser().GetLogin(),
+		Action:    inv.Action,
+		Resource: fmt.Sprintf(
+			"%s#%d",
+			inv.Event.GetRepo().GetFullName(),
+			inv.Event.GetIssue().GetNumber(),
+		),
+		Args:    invocationArgs(inv),
+		Outcome: OutcomeSucceeded,
+	}
+
+	if decision != nil {
+		record.Decision = "deny"
+		if decision.Allowed {
+			record.Decision = "allow"
+		}
+
+		record.Policies = decision.Policies
+	}
+
+	switch {
+	case errors.Is(err, command.ErrUnauthorized):
+		record.Outcome = OutcomeDenied
+		record.Error = err.Error()
+
+	case err != nil:
+		record.Outcome = OutcomeFailed
+		record.Error = err.Error()
+	}
+
+	return record
+}
+
+// invocationArgs returns the arguments of an invocation, including the flags parsed by the command.
+func invocationArgs(inv command.Invocation) []string {
+	if inv.Command == nil {
+		return inv.Args
+	}
+
+	var args []string
+
+	inv.Command.Flags().Visit(func(flag *pflag.Flag) {
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, v := range value.GetSlice() {
+				args = append(args, fmt.Sprintf("--%s=%s", flag.Name, v))
+			}
+
+			return
+		}
+
+		args = append(args, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+	})
+
+	return append(args, inv.Args...)
+}
@@ -0,0 +1,227 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/audit"
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+func TestMiddleware(t *testing.T) {
+	event := github.IssueCommentEvent{
+		Repo:    &github.Repository{FullName: github.Ptr("owner/repo")},
+		Issue:   &github.Issue{Number: github.Ptr(12)},
+		Comment: &github.IssueComment{User: &github.User{Login: github.Ptr("alice")}},
+	}
+
+	testCases := []struct {
+		name     string
+		handler  command.HandlerFunc
+		decision string
+		policies []string
+		outcome  audit.Outcome
+	}{
+		{
+			name: "succeeded",
+			handler: func(ctx context.Context, _ command.Invocation) error {
+				command.RecordDecision(
+					ctx,
+					command.Decision{Allowed: true, Policies: []string{"policy0"}},
+				)
+
+				return nil
+			},
+			decision: "allow",
+			policies: []string{"policy0"},
+			outcome:  audit.OutcomeSucceeded,
+		},
+		{
+			name: "failed",
+			handler: func(ctx context.Context, _ command.Invocation) error {
+				command.RecordDecision(ctx, command.Decision{Allowed: true})
+
+				return errors.New("something went wrong")
+			},
+			decision: "allow",
+			outcome:  audit.OutcomeFailed,
+		},
+		{
+			name: "denied",
+			handler: func(ctx context.Context, _ command.Invocation) error {
+				command.RecordDecision(ctx, command.Decision{Allowed: false})
+
+				return fmt.Errorf("%w: nope", command.ErrUnauthorized)
+			},
+			decision: "deny",
+			outcome:  audit.OutcomeDenied,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var records []audit.Record
+
+			sink := audit.SinkFunc(func(_ context.Context, record audit.Record) error {
+				records = append(records, record)
+
+				return nil
+			})
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			handler := command.Chain(testCase.handler, audit.Middleware(sink, logger))
+
+			_ = handler.Handle(context.Background(), command.Invocation{
+				Event:  event,
+				Args:   []string{"bug"},
+				Action: "label",
+			})
+
+			if len(records) != 1 {
+				t.Fatalf("expected exactly one record, got %d", len(records))
+			}
+
+			record := records[0]
+
+			if record.Principal != "alice" {
+				t.Errorf("expected principal %q, got %q", "alice", record.Principal)
+			}
+
+			if record.Resource != "owner/repo#12" {
+				t.Errorf("expected resource %q, got %q", "owner/repo#12", record.Resource)
+			}
+
+			if record.Action != "label" {
+				t.Errorf("expected action %q, got %q", "label", record.Action)
+			}
+
+			if record.Decision != testCase.decision {
+				t.Errorf("expected decision %q, got %q", testCase.decision, record.Decision)
+			}
+
+			if !slices.Equal(record.Policies, testCase.policies) {
+				t.Errorf("expected policies %v, got %v", testCase.policies, record.Policies)
+			}
+
+			if record.Outcome != testCase.outcome {
+				t.Errorf("expected outcome %q, got %q", testCase.outcome, record.Outcome)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Args(t *testing.T) {
+	cmd := &cobra.Command{Use: "lock"}
+	cmd.Flags().String("reason", "", "")
+	cmd.Flags().StringSlice("label", nil, "")
+
+	err := cmd.ParseFlags([]string{"--reason", "spam", "--label=a", "--label=b", "now"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []audit.Record
+
+	sink := audit.SinkFunc(func(_ context.Context, record audit.Record) error {
+		records = append(records, record)
+
+		return nil
+	})
+
+	handler := command.Chain(
+		command.HandlerFunc(func(context.Context, command.Invocation) error { return nil }),
+		audit.Middleware(sink, slog.New(slog.DiscardHandler)),
+	)
+
+	err = handler.Handle(t.Context(), command.Invocation{
+		Command: cmd,
+		Args:    cmd.Flags().Args(),
+		Action:  "lock",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected exactly one record, got %d", len(records))
+	}
+
+	expected := []string{"--label=a", "--label=b", "--reason=spam", "now"}
+	if args := records[0].Args; !slices.Equal(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		outcome audit.Outcome
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:    "invalid",
+			err:     errors.New("accepts 1 arg(s), received 0"),
+			outcome: audit.OutcomeInvalid,
+		},
+		{
+			name:    "denied",
+			err:     fmt.Errorf("%w: nope", command.ErrUnauthorized),
+			outcome: audit.OutcomeDenied,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var records []audit.Record
+
+			sink := audit.SinkFunc(func(_ context.Context, record audit.Record) error {
+				records = append(records, record)
+
+				return nil
+			})
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			handler := command.Chain(
+				command.HandlerFunc(func(context.Context, command.Invocation) error {
+					return testCase.err
+				}),
+				audit.ValidationMiddleware(sink, logger),
+			)
+
+			err := handler.Handle(context.Background(), command.Invocation{Action: "label"})
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("expected error %v, got %v", testCase.err, err)
+			}
+
+			// Valid commands are recorded when executed
+			if testCase.err == nil {
+				if len(records) > 0 {
+					t.Errorf("expected no records, got %v", records)
+				}
+
+				return
+			}
+
+			if len(records) != 1 {
+				t.Fatalf("expected exactly one record, got %d", len(records))
+			}
+
+			if record := records[0]; record.Outcome != testCase.outcome {
+				t.Errorf("expected outcome %q, got %q", testCase.outcome, record.Outcome)
+			}
+		})
+	}
+}
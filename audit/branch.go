@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// BranchSink appends records to a single Markdown file committed to a branch.
+//
+// The branch is created (without history) if it does not exist.
+// Every record results in a new commit, so the log is append-only and tamper-evident.
+//
+// The branch is updated with a compare-and-swap: the update is rejected if somebody else
+// (eg. a concurrent workflow run) committed to the branch in the meantime, in which case appending is retried.
+type BranchSink struct {
+	Client *github.Client
+
+	Owner string
+	Repo  string
+
+	// Branch to commit the log to.
+	Branch string
+
+	// Path of the log file on the branch.
+	//
+	// Defaults to [DefaultBranchPath].
+	Path string
+
+	mu sync.Mutex
+}
+
+// DefaultBranchPath is the default path of the log file committed by [BranchSink].
+const DefaultBranchPath = "AUDIT.md"
+
+// branchSinkAttempts is the number of times appending a record is attempted on conflicting updates.
+const branchSinkAttempts = 5
+
+// Record implements [Sink].
+func (s *BranchSink) Record(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row := markdownTableRow(record)
+	message := fmt.Sprintf("audit: %s %s on %s", record.Principal, record.Action, record.Resource)
+
+	var err error
+
+	for range branchSinkAttempts {
+		err = s.append(ctx, row, message)
+		if !isConflict(err) {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("appending audit log to branch %s: %w", s.Branch, err)
+	}
+
+	return nil
+}
+
+func (s *BranchSink) path() string {
+	if s.Path == "" {
+		return DefaultBranchPath
+	}
+
+	return s.Path
+}
+
+func (s *BranchSink) append(ctx context.Context, row string, message string) error {
+	ref, resp, err := s.Client.Git.GetRef(ctx, s.Owner, s.Repo, "heads/"+s.Branch)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return s.createBranch(ctx, markdownTableHeader+row, message)
+	} else if err != nil {
+		return err
+	}
+
+	parent := ref.GetObject().GetSHA()
+
+	parentCommit, _, err := s.Client.Git.GetCommit(ctx, s.Owner, s.Repo, parent)
+	if err != nil {
+		return err
+	}
+
+	content, err := s.read(ctx, parent)
+	if err != nil {
+		return err
+	}
+
+	tree, _, err := s.Client.Git.CreateTree(ctx, s.Owner, s.Repo, parentCommit.GetTree().GetSHA(), []*github.TreeEntry{
+		{
+			Path:    github.Ptr(s.path()),
+			Mode:    github.Ptr("100644"),
+			Type:    github.Ptr("blob"),
+			Content: github.Ptr(content + row),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	commit, _, err := s.Client.Git.CreateCommit(ctx, s.Owner, s.Repo, &github.Commit{
+		Message: github.Ptr(message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: github.Ptr(parent)}},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	// Not forcing the update makes it fail if the branch moved since it was read
+	_, _, err = s.Client.Git.UpdateRef(ctx, s.Owner, s.Repo, &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + s.Branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}, false)
+
+	return err
+}
+
+// read returns the content of the log file at a commit (or an empty table if the file does not exist yet).
+func (s *BranchSink) read(ctx context.Context, ref string) (string, error) {
+	file, _, resp, err := s.Client.Repositories.GetContents(
+		ctx,
+		s.Owner,
+		s.Repo,
+		s.path(),
+		&github.RepositoryContentGetOptions{Ref: ref},
+	)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return markdownTableHeader, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// The contents API does not return the content of large files
+	if file.GetEncoding() == "none" {
+		content, _, err := s.Client.Git.GetBlobRaw(ctx, s.Owner, s.Repo, file.GetSHA())
+		if err != nil {
+			return "", err
+		}
+
+		return string(content), nil
+	}
+
+	return file.GetContent()
+}
+
+// createBranch creates an orphan branch containing only the log file.
+func (s *BranchSink) createBranch(ctx context.Context, content string, message string) error {
+	tree, _, err := s.Client.Git.CreateTree(ctx, s.Owner, s.Repo, "", []*github.TreeEntry{
+		{
+			Path:    github.Ptr(s.path()),
+			Mode:    github.Ptr("100644"),
+			Type:    github.Ptr("blob"),
+			Content: github.Ptr(content),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	commit, _, err := s.Client.Git.CreateCommit(ctx, s.Owner, s.Repo, &github.Commit{
+		Message: github.Ptr(message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = s.Client.Git.CreateRef(ctx, s.Owner, s.Repo, &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + s.Branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	})
+
+	return err
+}
+
+// isConflict reports whether err was caused by a concurrent update of the log.
+func isConflict(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+
+	switch errResp.Response.StatusCode {
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,190 @@
+package audit_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/audit"
+)
+
+// fakeBranch is a test GitHub API server storing a single file on a single branch.
+type fakeBranch struct {
+	// head is the commit the branch points to.
+	head string
+
+	// files are the contents of the log file by commit.
+	files map[string]string
+
+	// trees are the contents of the log file in created trees.
+	trees map[string]string
+
+	// commits are the created commits.
+	commits map[string]fakeCommit
+
+	// concurrent is committed to the branch before the next ref update (simulating a concurrent update).
+	concurrent string
+
+	refUpdates int
+}
+
+type fakeCommit struct {
+	Tree    string   `json:"tree"`
+	Parents []string `json:"parents"`
+}
+
+func (f *fakeBranch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/repos/owner/repo/"
+
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+
+	switch {
+	case r.Method == http.MethodGet && path == "git/ref/heads/audit":
+		writeJSON(w, github.Reference{Object: &github.GitObject{SHA: github.Ptr(f.head)}})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "git/commits/"):
+		sha := strings.TrimPrefix(path, "git/commits/")
+		writeJSON(w, github.Commit{SHA: github.Ptr(sha), Tree: &github.Tree{SHA: github.Ptr("tree-" + sha)}})
+
+	case r.Method == http.MethodGet && path == "contents/AUDIT.md":
+		content, ok := f.files[r.URL.Query().Get("ref")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		writeJSON(w, github.RepositoryContent{
+			Type:     github.Ptr("file"),
+			Encoding: github.Ptr("base64"),
+			Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+		})
+
+	case r.Method == http.MethodPost && path == "git/trees":
+		var body struct {
+			BaseTree string              `json:"base_tree"`
+			Tree     []*github.TreeEntry `json:"tree"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		sha := "tree" + string(rune('0'+len(f.trees)))
+		f.trees[sha] = body.Tree[0].GetContent()
+
+		writeJSON(w, github.Tree{SHA: github.Ptr(sha)})
+
+	case r.Method == http.MethodPost && path == "git/commits":
+		var body fakeCommit
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		sha := "commit" + string(rune('0'+len(f.commits)))
+		f.commits[sha] = body
+
+		writeJSON(w, github.Commit{SHA: github.Ptr(sha)})
+
+	case r.Method == http.MethodPatch && path == "git/refs/heads/audit":
+		var body struct {
+			SHA   string `json:"sha"`
+			Force bool   `json:"force"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		f.refUpdates++
+
+		if f.concurrent != "" {
+			f.files["concurrent"] = f.files[f.head] + f.concurrent
+			f.head = "concurrent"
+			f.concurrent = ""
+		}
+
+		if body.Force {
+			http.Error(w, "unexpected forced update", http.StatusBadRequest)
+
+			return
+		}
+
+		commit := f.commits[body.SHA]
+
+		// The parent of the new commit must be the head of the branch (fast-forward)
+		if len(commit.Parents) != 1 || commit.Parents[0] != f.head {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			writeJSON(w, map[string]string{"message": "Update is not a fast forward"})
+
+			return
+		}
+
+		f.files[body.SHA] = f.trees[commit.Tree]
+		f.head = body.SHA
+
+		writeJSON(w, github.Reference{Object: &github.GitObject{SHA: github.Ptr(body.SHA)}})
+
+	default:
+		http.Error(w, "unexpected request: "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestBranchSink(t *testing.T) {
+	const header = "| Time | Principal | Action | Resource | Arguments | Decision | Policies | Outcome | Duration |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- |\n"
+
+	fake := &fakeBranch{
+		head:       "initial",
+		files:      map[string]string{"initial": header},
+		trees:      map[string]string{},
+		commits:    map[string]fakeCommit{},
+		concurrent: "| concurrent |\n",
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	sink := &audit.BranchSink{
+		Client: client,
+		Owner:  "owner",
+		Repo:   "repo",
+		Branch: "audit",
+	}
+
+	err := sink.Record(t.Context(), audit.Record{
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Principal: "alice",
+		Action:    "lock",
+		Resource:  "owner/repo#12",
+		Args:      []string{"--reason=spam"},
+		Decision:  "allow",
+		Outcome:   audit.OutcomeSucceeded,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first update conflicts with the concurrent one, so it is retried
+	if fake.refUpdates != 2 {
+		t.Errorf("expected 2 ref updates, got %d", fake.refUpdates)
+	}
+
+	expected := header +
+		"| concurrent |\n" +
+		"| 2026-01-02T03:04:05Z | @alice | `lock` | owner/repo#12 | \\-\\-reason\\=spam | allow |  | succeeded | 0s |\n"
+
+	if content := fake.files[fake.head]; content != expected {
+		t.Errorf("unexpected log:\n%s\nexpected:\n%s", content, expected)
+	}
+}
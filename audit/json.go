@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONSink writes records to a writer as JSON lines.
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONSink returns a new [JSONSink].
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{
+		w: w,
+	}
+}
+
+// Record implements [Sink].
+func (s *JSONSink) Record(_ context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return json.NewEncoder(s.w).Encode(record)
+}
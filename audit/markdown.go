@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sagikazarmark/octoslash/internal/markdown"
+)
+
+const markdownTableHeader = "| Time | Principal | Action | Resource | Arguments | Decision | Policies | Outcome | Duration |\n" +
+	"| --- | --- | --- | --- | --- | --- | --- | --- | --- |\n"
+
+// markdownTableRow formats a record as a Markdown table row.
+//
+// The action and the arguments come from comments: they are escaped, so they cannot inject Markdown into the table.
+func markdownTableRow(record Record) string {
+	escape := strings.NewReplacer("|", `\|`, "\r", "", "\n", " ")
+
+	cells := []string{
+		record.Time.UTC().Format(time.RFC3339),
+		"@" + escape.Replace(record.Principal),
+		markdown.CodeSpan(record.Action),
+		escape.Replace(record.Resource),
+		escape.Replace(markdown.Escape(strings.Join(record.Args, " "))),
+		escape.Replace(record.Decision),
+		escape.Replace(strings.Join(record.Policies, ", ")),
+		string(record.Outcome),
+		record.Duration.Round(time.Millisecond).String(),
+	}
+
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// SummarySink writes records as a Markdown table to a GitHub Actions job summary.
+//
+// The table header is written before the first record.
+type SummarySink struct {
+	mu            sync.Mutex
+	w             io.Writer
+	headerWritten bool
+}
+
+// NewSummarySink returns a new [SummarySink].
+//
+// w is typically the file referenced by the GITHUB_STEP_SUMMARY environment variable, opened for appending.
+func NewSummarySink(w io.Writer) *SummarySink {
+	return &SummarySink{
+		w: w,
+	}
+}
+
+// Record implements [Sink].
+func (s *SummarySink) Record(_ context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.headerWritten {
+		_, err := io.WriteString(s.w, "### Octoslash audit log\n\n"+markdownTableHeader)
+		if err != nil {
+			return fmt.Errorf("writing job summary: %w", err)
+		}
+
+		s.headerWritten = true
+	}
+
+	_, err := io.WriteString(s.w, markdownTableRow(record))
+	if err != nil {
+		return fmt.Errorf("writing job summary: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package audit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sagikazarmark/octoslash/audit"
+)
+
+func TestSummarySink(t *testing.T) {
+	var buf bytes.Buffer
+
+	sink := audit.NewSummarySink(&buf)
+
+	records := []audit.Record{
+		{
+			Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Principal: "alice",
+			Action:    "add-label",
+			Resource:  "owner/repo#12",
+			Args:      []string{"bug"},
+			Decision:  "allow",
+			Policies:  []string{"policy0"},
+			Outcome:   audit.OutcomeSucceeded,
+			Duration:  1500 * time.Millisecond,
+		},
+		{
+			Time:      time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC),
+			Principal: "bob",
+			Action:    "close",
+			Resource:  "owner/repo#12",
+			Decision:  "deny",
+			Outcome:   audit.OutcomeDenied,
+		},
+		{
+			// Commands come from comments: they must not inject Markdown into the table
+			Time:      time.Date(2026, 1, 2, 3, 4, 7, 0, time.UTC),
+			Principal: "mallory",
+			Action:    "x` [click](https://example.com) `",
+			Resource:  "owner/repo#12",
+			Args:      []string{"![img](https://example.com/x.png)", "@alice"},
+			Outcome:   audit.OutcomeInvalid,
+		},
+	}
+
+	for _, record := range records {
+		if err := sink.Record(t.Context(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "### Octoslash audit log\n\n" +
+		"| Time | Principal | Action | Resource | Arguments | Decision | Policies | Outcome | Duration |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| 2026-01-02T03:04:05Z | @alice | `add-label` | owner/repo#12 | bug | allow | policy0 | succeeded | 1.5s |\n" +
+		"| 2026-01-02T03:04:06Z | @bob | `close` | owner/repo#12 |  | deny |  | denied | 0s |\n" +
+		"| 2026-01-02T03:04:07Z | @mallory | `` x` [click](https://example.com) ` `` | owner/repo#12 | " +
+		"\\!\\[img\\]\\(https\\:\\/\\/example\\.com\\/x\\.png\\) \\@alice |  |  | invalid | 0s |\n"
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("unexpected summary\nexpected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/cedar-policy/cedar-go"
 	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/command"
 )
 
 type Authorizer struct {
@@ -34,12 +36,14 @@ func (a Authorizer) Authorize(
 	action string,
 ) error {
 	request := newRequest(event, action)
+	request.Context = NewRecord(command.AuthorizationContextFromContext(ctx))
 
 	a.Logger.Debug(
 		"authorizing request",
 		slog.String("principal", request.Principal.String()),
 		slog.String("resource", request.Resource.String()),
 		slog.String("action", request.Action.String()),
+		slog.String("context", request.Context.String()),
 	)
 
 	// TODO: clean this up
@@ -50,10 +54,14 @@ func (a Authorizer) Authorize(
 		a.Entities,
 	}
 
-	ok, _ := cedar.Authorize(a.Policies, entities, request)
-	if !ok {
+	decision, diagnostic := cedar.Authorize(a.Policies, entities, request)
+
+	command.RecordDecision(ctx, newDecision(decision, diagnostic))
+
+	if decision != cedar.Allow {
 		return fmt.Errorf(
-			"principal %s is not authorized to perform %s on %s",
+			"%w: principal %s is not authorized to perform %s on %s",
+			command.ErrUnauthorized,
 			request.Principal.String(),
 			request.Action.String(),
 			request.Resource.String(),
@@ -63,6 +71,22 @@ func (a Authorizer) Authorize(
 	return nil
 }
 
+func newDecision(decision cedar.Decision, diagnostic cedar.Diagnostic) command.Decision {
+	d := command.Decision{
+		Allowed: decision == cedar.Allow,
+	}
+
+	for _, reason := range diagnostic.Reasons {
+		d.Policies = append(d.Policies, string(reason.PolicyID))
+	}
+
+	for _, err := range diagnostic.Errors {
+		d.Errors = append(d.Errors, err.String())
+	}
+
+	return d
+}
+
 func newRequest(event github.IssueCommentEvent, action string) cedar.Request {
 	return cedar.Request{
 		Principal: NewUserID(event.GetComment().GetUser()),
@@ -0,0 +1,59 @@
+package authz_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/cedar-policy/cedar-go"
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/authz"
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+func TestAuthorizer(t *testing.T) {
+	policies, err := cedar.NewPolicySetFromBytes("policy.cedar", []byte(`
+permit (principal, action == Action::"milestone", resource)
+when { context has milestone && context.milestone == "v1" };
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := authz.NewAuthorizer(policies, cedar.EntityMap{}, slog.New(slog.DiscardHandler))
+
+	event := github.IssueCommentEvent{
+		Issue:   &github.Issue{ID: github.Ptr(int64(1))},
+		Comment: &github.IssueComment{User: &github.User{ID: github.Ptr(int64(2))}},
+	}
+
+	testCases := []struct {
+		milestone string
+		allowed   bool
+	}{
+		{milestone: "v1", allowed: true},
+		{milestone: "v2", allowed: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.milestone, func(t *testing.T) {
+			ctx, decision := command.WithDecisionRecorder(t.Context())
+			ctx = command.WithAuthorizationContext(ctx, map[string]any{"milestone": testCase.milestone})
+
+			err := authorizer.Authorize(ctx, event, "milestone")
+
+			if testCase.allowed && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !testCase.allowed && !errors.Is(err, command.ErrUnauthorized) {
+				t.Fatalf("expected an unauthorized error, got %v", err)
+			}
+
+			if d := decision(); d == nil || d.Allowed != testCase.allowed {
+				t.Errorf("expected recorded decision allowed=%t, got %+v", testCase.allowed, d)
+			}
+		})
+	}
+}
@@ -65,6 +65,12 @@ func NewIssueOrPullRequest(issue *github.Issue, repo *github.Repository) cedar.E
 
 	attributes := cedar.RecordMap{
 		cedar.String("number"): cedar.Long(issue.GetNumber()),
+		cedar.String("state"):  cedar.String(issue.GetState()),
+		cedar.String("locked"): cedar.Boolean(issue.GetLocked()),
+	}
+
+	if user := issue.GetUser(); user != nil {
+		attributes[cedar.String("author")] = NewUserID(user)
 	}
 
 	var labels []cedar.Value
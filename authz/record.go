@@ -0,0 +1,52 @@
+package authz
+
+import (
+	"fmt"
+
+	"github.com/cedar-policy/cedar-go"
+)
+
+// NewRecord converts a set of attributes to a Cedar record.
+//
+// Strings, booleans, integers and string slices are converted to their Cedar counterparts,
+// Cedar values are used as is and any other value is converted to its string representation.
+func NewRecord(attrs map[string]any) cedar.Record {
+	record := make(cedar.RecordMap, len(attrs))
+
+	for key, value := range attrs {
+		record[cedar.String(key)] = newValue(value)
+	}
+
+	return cedar.NewRecord(record)
+}
+
+func newValue(value any) cedar.Value {
+	switch v := value.(type) {
+	case cedar.Value:
+		return v
+
+	case string:
+		return cedar.String(v)
+
+	case bool:
+		return cedar.Boolean(v)
+
+	case int:
+		return cedar.Long(v)
+
+	case int64:
+		return cedar.Long(v)
+
+	case []string:
+		values := make([]cedar.Value, 0, len(v))
+
+		for _, s := range v {
+			values = append(values, cedar.String(s))
+		}
+
+		return cedar.NewSet(values...)
+
+	default:
+		return cedar.String(fmt.Sprint(v))
+	}
+}
@@ -2,43 +2,192 @@ package builtin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
+	"slices"
 
 	"github.com/google/go-github/v74/github"
 	"github.com/spf13/cobra"
 
+	"github.com/sagikazarmark/octoslash"
 	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/parser"
 )
 
-type Provider struct{}
+type Provider struct {
+	// StrictLabels refuses to add labels that do not exist in the repository.
+	StrictLabels bool
 
+	// LabelScopes configures label families (see [LabelScope]).
+	LabelScopes []LabelScope
+
+	// Registry contains additional commands registered by library users.
+	//
+	// Builtin commands take precedence over registered commands with the same name.
+	Registry *octoslash.Registry
+}
+
+// NewCommandProvider returns a provider for the builtin commands (without repository configuration).
+//
+// Shorthand commands of label scopes conflicting with other commands are skipped (see [CommandProvider.NewCommand]).
 func (p Provider) NewCommandProvider(
 	client *github.Client,
 	logger *slog.Logger,
 ) command.CommandProvider {
-	return CommandProvider{
-		Client: client,
-		Logger: logger,
+	return p.commandProvider(CommandProvider{
+		Client:       client,
+		Logger:       logger,
+		StrictLabels: p.StrictLabels,
+		LabelScopes:  p.LabelScopes,
+	})
+}
+
+// NewConfiguredCommandProvider returns a provider for the builtin commands
+// customized by the configuration in fsys (see [ConfigFileName]).
+func (p Provider) NewConfiguredCommandProvider(
+	client *github.Client,
+	logger *slog.Logger,
+	fsys fs.FS,
+) (command.CommandProvider, error) {
+	config, err := LoadConfig(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("loading builtin command configuration: %w", err)
+	}
+
+	labels := p.addLabelHandler(client, logger, config)
+
+	provider := CommandProvider{
+		Client:       client,
+		Logger:       logger,
+		StrictLabels: labels.StrictLabels,
+		LabelScopes:  labels.Scopes,
+		Config:       config,
+	}
+
+	// Validate the configuration against the command tree early
+	rootCmd, err := provider.newCommand(github.IssueCommentEvent{})
+	err = errors.Join(err, config.apply(rootCmd))
+	if err != nil {
+		return nil, fmt.Errorf("invalid builtin command configuration: %w", err)
+	}
+
+	return p.commandProvider(provider), nil
+}
+
+// NewConfiguredAddLabelHandler returns a handler adding labels like the builtin add-label command
+// customized by the configuration in fsys (see [ConfigFileName]).
+//
+// It lets other command providers (eg. custom commands) honor strict labels and label scopes.
+func (p Provider) NewConfiguredAddLabelHandler(
+	client *github.Client,
+	logger *slog.Logger,
+	fsys fs.FS,
+) (AddLabelHandler, error) {
+	config, err := LoadConfig(fsys)
+	if err != nil {
+		return AddLabelHandler{}, fmt.Errorf("loading builtin command configuration: %w", err)
+	}
+
+	return p.addLabelHandler(client, logger, config), nil
+}
+
+func (p Provider) addLabelHandler(client *github.Client, logger *slog.Logger, config Config) AddLabelHandler {
+	return AddLabelHandler{
+		Client:       client,
+		Logger:       logger,
+		StrictLabels: p.StrictLabels || config.Labels.Strict,
+		Scopes:       append(slices.Clone(p.LabelScopes), config.labelScopes()...),
+	}
+}
+
+// commandProvider adds the commands of the registry (if any) to provider.
+func (p Provider) commandProvider(provider CommandProvider) command.CommandProvider {
+	if p.Registry != nil {
+		return command.CommandProviders{provider, p.Registry.NewCommandProvider(provider.Client, provider.Logger)}
+	}
+
+	return provider
+}
+
+// NewConfiguredTrigger returns the trigger configured in fsys (see [ConfigFileName]) or fallback if it is not set.
+func (p Provider) NewConfiguredTrigger(
+	fsys fs.FS,
+	fallback parser.Trigger,
+) (parser.Trigger, error) {
+	config, err := LoadConfig(fsys)
+	if err != nil {
+		return fallback, fmt.Errorf("loading builtin command configuration: %w", err)
+	}
+
+	trigger, err := config.trigger(fallback)
+	if err != nil {
+		return fallback, fmt.Errorf("invalid builtin command configuration: %w", err)
 	}
+
+	return trigger, nil
 }
 
 type CommandProvider struct {
 	Client *github.Client
 	Logger *slog.Logger
+
+	// StrictLabels refuses to add labels that do not exist in the repository.
+	StrictLabels bool
+
+	// LabelScopes configures label families (see [LabelScope]).
+	LabelScopes []LabelScope
+
+	// Config customizes builtin commands (eg. disables or renames them).
+	Config Config
 }
 
 func (p CommandProvider) NewCommand(event github.IssueCommentEvent) *cobra.Command {
+	rootCmd, err := p.newCommand(event)
+	if err != nil {
+		p.Logger.Warn("adding label scope commands", slog.Any("error", err))
+	}
+
+	err = p.Config.apply(rootCmd)
+	if err != nil {
+		p.Logger.Warn("applying builtin command configuration", slog.Any("error", err))
+	}
+
+	return rootCmd
+}
+
+// newCommand creates the builtin command tree.
+//
+// Shorthand commands of label scopes conflicting with other commands are skipped and reported in the returned error.
+// They are not added at all when the add-label command is disabled.
+func (p CommandProvider) newCommand(event github.IssueCommentEvent) (*cobra.Command, error) {
 	rootCmd := &cobra.Command{
 		Use:   "octoslash",
 		Short: "Slash commands for GitHub issues and pull requests",
 	}
 
+	addLabelHandler := AddLabelHandler{
+		Client:       p.Client,
+		Logger:       p.Logger,
+		StrictLabels: p.StrictLabels,
+		Scopes:       p.LabelScopes,
+	}
+
+	addLabelCmd := NewAddLabelCommandWithHandler(event, addLabelHandler)
+
 	rootCmd.AddCommand(
 		NewCloseCommand(event, p.Client, p.Logger),
+		NewReopenCommand(event, p.Client, p.Logger),
+
+		NewLockCommand(event, p.Client, p.Logger),
+		NewUnlockCommand(event, p.Client, p.Logger),
 
-		NewAddLabelCommand(event, p.Client, p.Logger),
+		addLabelCmd,
 		NewRemoveLabelCommand(event, p.Client, p.Logger),
 
+		NewMilestoneCommand(event, p.Client, p.Logger),
+
 		NewAssignCommand(event, p.Client, p.Logger),
 		NewSelfAssignCommand(event, p.Client, p.Logger),
 		NewUnassignCommand(event, p.Client, p.Logger),
@@ -47,7 +196,32 @@ func (p CommandProvider) NewCommand(event github.IssueCommentEvent) *cobra.Comma
 		NewWorkflowRunCommand(event, p.Client, p.Logger),
 	)
 
-	return rootCmd
+	// Shorthand commands add labels under the add-label action: they must not bypass disabling it
+	if slices.ContainsFunc(p.Config.Disable, func(name string) bool { return findCommand(rootCmd, name) == addLabelCmd }) {
+		return rootCmd, nil
+	}
+
+	var errs []error
+
+	for _, scope := range p.LabelScopes {
+		if scope.Command == "" {
+			continue
+		}
+
+		if findCommand(rootCmd, scope.Command) != nil {
+			errs = append(errs, fmt.Errorf(
+				"labels: scopes: %s: command %q conflicts with an existing command",
+				scope.Prefix,
+				scope.Command,
+			))
+
+			continue
+		}
+
+		rootCmd.AddCommand(newLabelScopeCommand(event, scope, addLabelHandler))
+	}
+
+	return rootCmd, errors.Join(errs...)
 }
 
 type commandHandler[T any] interface {
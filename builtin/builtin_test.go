@@ -0,0 +1,353 @@
+package builtin_test
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+func newRepository() *octoslashtest.Repository {
+	return &octoslashtest.Repository{
+		Owner:      "owner",
+		Name:       "repo",
+		Labels:     []string{"bug", "kind/bug", "kind/feature", "priority/low", "priority/high"},
+		Milestones: []octoslashtest.Milestone{{Number: 1, Title: "v1.0.0"}, {Number: 2, Title: "v2.0.0"}},
+		Issues: map[int]*octoslashtest.Issue{
+			1: {
+				Number: 1,
+				Author: "octocat",
+				Labels: []string{"kind/bug", "priority/low"},
+			},
+			2: {
+				Number:      2,
+				Author:      "octocat",
+				PullRequest: true,
+				HeadRef:     "feature",
+			},
+		},
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	testCases := []struct {
+		name  string
+		issue int
+		body  string
+		check func(t *testing.T, server *octoslashtest.Server, repo *octoslashtest.Repository)
+	}{
+		{
+			name: "close",
+			body: "/close not_planned",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertState(t, repo.Issues[1], "closed", "not_planned")
+			},
+		},
+		{
+			name: "reopen",
+			body: "/close\n/reopen",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertState(t, repo.Issues[1], "open", "")
+			},
+		},
+		{
+			name: "add labels",
+			body: "/add-label bug kind/feature",
+			check: func(t *testing.T, server *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertRequests(t, server, "POST /repos/owner/repo/issues/1/labels")
+				octoslashtest.AssertLabels(
+					t,
+					repo.Issues[1],
+					"bug",
+					"kind/bug",
+					"kind/feature",
+					"priority/low",
+				)
+			},
+		},
+		{
+			name: "add labels case-insensitively",
+			body: "/add-label BUG",
+			check: func(t *testing.T, server *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "bug", "kind/bug", "priority/low")
+
+				// Labels of the repository are only looked up with strict labels
+				for _, r := range server.Requests() {
+					if r.String() == "GET /repos/owner/repo/labels" {
+						t.Error("unexpected request listing repository labels")
+					}
+				}
+			},
+		},
+		{
+			name: "remove labels",
+			body: "/remove-label kind/*",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "priority/low")
+			},
+		},
+		{
+			name: "remove labels across slashes",
+			body: "/remove-label 'K*'",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "priority/low")
+			},
+		},
+		{
+			name: "remove labels added earlier",
+			body: "/add-label kind/feature\n/remove-label 'kind/*'",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "priority/low")
+			},
+		},
+		{
+			name: "assign",
+			body: "/assign alice\n/self-assign",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertAssignees(t, repo.Issues[1], "alice", "octocat")
+			},
+		},
+		{
+			name: "lock",
+			body: "/lock too heated",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				if issue := repo.Issues[1]; !issue.Locked || issue.LockReason != "too heated" {
+					t.Errorf("expected issue to be locked as too heated, got %+v", issue)
+				}
+			},
+		},
+		{
+			name: "milestone",
+			body: "/milestone v2",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				if milestone := repo.Issues[1].Milestone; milestone != 2 {
+					t.Errorf("expected milestone 2, got %d", milestone)
+				}
+			},
+		},
+		{
+			name:  "workflow run",
+			issue: 2,
+			body:  "/workflow-run --watch ci.yaml",
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertDispatched(t, repo, "ci.yaml", "feature")
+				octoslashtest.AssertComment(t, repo.Issues[2], "started")
+				octoslashtest.AssertComment(t, repo.Issues[2], "completed with conclusion `success`")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+			repo := server.AddRepository(newRepository())
+
+			issue := testCase.issue
+			if issue == 0 {
+				issue = 1
+			}
+
+			handler := octoslash.EventHandler{
+				Dispatcher: command.CobraDispatcher{
+					Authorizer: octoslashtest.AllowAll(),
+					CommandProvider: builtin.CommandProvider{
+						Client: server.Client(),
+						Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+					},
+				},
+			}
+
+			event := octoslashtest.NewIssueCommentEvent(
+				testCase.body,
+				octoslashtest.WithIssue(repo.Issues[issue]),
+			)
+
+			results, err := handler.Execute(t.Context(), event)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, result := range results {
+				if result.Status != octoslash.ResultSucceeded {
+					t.Errorf("expected /%s to succeed, got %s", result.Command, result.Status)
+				}
+			}
+
+			testCase.check(t, server, repo)
+		})
+	}
+}
+
+func TestCommandProvider_Denied(t *testing.T) {
+	server := octoslashtest.NewServer(t)
+	repo := server.AddRepository(newRepository())
+
+	handler := octoslash.EventHandler{
+		Dispatcher: command.CobraDispatcher{
+			Authorizer: octoslashtest.Deny("close"),
+			CommandProvider: builtin.CommandProvider{
+				Client: server.Client(),
+				Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			},
+		},
+		ExecutionPolicy: octoslash.ContinueOnError,
+	}
+
+	event := octoslashtest.NewIssueCommentEvent(
+		"/close\n/add-label bug",
+		octoslashtest.WithIssue(repo.Issues[1]),
+	)
+
+	results, _ := handler.Execute(t.Context(), event)
+
+	octoslashtest.AssertResults(t, results, octoslash.ResultDenied, octoslash.ResultSucceeded)
+	octoslashtest.AssertState(t, repo.Issues[1], "open", "")
+	octoslashtest.AssertRequests(t, server, "POST /repos/owner/repo/issues/1/labels")
+}
+
+func TestProvider_LabelScopes(t *testing.T) {
+	fsys := fstest.MapFS{
+		builtin.ConfigFileName: &fstest.MapFile{Data: []byte(`
+labels:
+  scopes:
+    - prefix: priority/
+      exclusive: true
+      command: priority
+`)},
+	}
+
+	testCases := []struct {
+		name       string
+		authorizer command.Authorizer
+		status     octoslash.ResultStatus
+		labels     []string
+	}{
+		{
+			name:       "allowed",
+			authorizer: octoslashtest.AllowAll(),
+			status:     octoslash.ResultSucceeded,
+			labels:     []string{"kind/bug", "priority/high"},
+		},
+		{
+			// Shorthand commands are authorized as add-label
+			name:       "denied",
+			authorizer: octoslashtest.Deny("add-label"),
+			status:     octoslash.ResultDenied,
+			labels:     []string{"kind/bug", "priority/low"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+			repo := server.AddRepository(newRepository())
+
+			provider, err := builtin.Provider{}.NewConfiguredCommandProvider(
+				server.Client(),
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+				fsys,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := octoslash.EventHandler{
+				Dispatcher: command.CobraDispatcher{
+					Authorizer:      testCase.authorizer,
+					CommandProvider: provider,
+				},
+			}
+
+			event := octoslashtest.NewIssueCommentEvent(
+				"/priority high",
+				octoslashtest.WithIssue(repo.Issues[1]),
+			)
+
+			results, _ := handler.Execute(t.Context(), event)
+
+			octoslashtest.AssertResults(t, results, testCase.status)
+			octoslashtest.AssertLabels(t, repo.Issues[1], testCase.labels...)
+		})
+	}
+}
+
+func TestProvider_LabelScopesAddLabelDisabled(t *testing.T) {
+	fsys := fstest.MapFS{
+		builtin.ConfigFileName: &fstest.MapFile{Data: []byte(`
+disable:
+  - add-label
+labels:
+  scopes:
+    - prefix: priority/
+      exclusive: true
+      command: priority
+`)},
+	}
+
+	server := octoslashtest.NewServer(t)
+	repo := server.AddRepository(newRepository())
+
+	provider, err := builtin.Provider{}.NewConfiguredCommandProvider(
+		server.Client(),
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		fsys,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := octoslash.EventHandler{
+		Dispatcher: command.CobraDispatcher{
+			Authorizer:      octoslashtest.AllowAll(),
+			CommandProvider: provider,
+		},
+	}
+
+	event := octoslashtest.NewIssueCommentEvent(
+		"/priority high",
+		octoslashtest.WithIssue(repo.Issues[1]),
+	)
+
+	results, _ := handler.Execute(t.Context(), event)
+
+	octoslashtest.AssertResults(t, results, octoslash.ResultInvalid)
+	octoslashtest.AssertLabels(t, repo.Issues[1], "kind/bug", "priority/low")
+}
+
+func TestProvider_LabelScopeConflict(t *testing.T) {
+	fsys := fstest.MapFS{
+		builtin.ConfigFileName: &fstest.MapFile{Data: []byte(`
+labels:
+  scopes:
+    - prefix: status/
+      command: close
+`)},
+	}
+
+	_, err := builtin.Provider{}.NewConfiguredCommandProvider(
+		nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		fsys,
+	)
+	if err == nil || !strings.Contains(err.Error(), `command "close" conflicts with an existing command`) {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+
+	// Conflicting shorthand commands are skipped if the configuration is not validated
+	provider := builtin.Provider{
+		LabelScopes: []builtin.LabelScope{{Prefix: "status/", Command: "close"}},
+	}.NewCommandProvider(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	rootCmd := provider.NewCommand(octoslashtest.NewIssueCommentEvent(""))
+
+	cmd, _, err := rootCmd.Find([]string{"close"})
+	if err != nil || cmd.Annotations[command.ActionAnnotation] == "add-label" {
+		t.Errorf("expected close not to be overridden by the shorthand command, got %v", cmd)
+	}
+}
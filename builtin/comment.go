@@ -0,0 +1,44 @@
+package builtin
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Comment represents a command to post a comment on an issue or pull request.
+type Comment struct {
+	Repo  *github.Repository
+	Issue *github.Issue
+	Body  string
+}
+
+// CommentHandler handles the [Comment] command.
+type CommentHandler struct {
+	Client *github.Client
+	Logger *slog.Logger
+}
+
+// Handle executes the [Comment] command.
+func (h CommentHandler) Handle(ctx context.Context, cmd Comment) error {
+	repo := cmd.Repo
+	issue := cmd.Issue
+
+	h.Logger.Info("posting comment", slog.Int("number", issue.GetNumber()))
+
+	_, _, err := h.Client.Issues.CreateComment(
+		ctx,
+		repo.GetOwner().GetLogin(),
+		repo.GetName(),
+		issue.GetNumber(),
+		&github.IssueComment{
+			Body: github.Ptr(cmd.Body),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/parser"
+)
+
+// ConfigFileName is the name of the file customizing builtin commands in the configuration directory.
+const ConfigFileName = "builtin.yaml"
+
+// Config customizes builtin commands.
+type Config struct {
+	// Disable lists builtin commands to disable.
+	Disable []string `yaml:"disable"`
+
+	// Commands customizes individual builtin commands by name.
+	Commands map[string]CommandConfig `yaml:"commands"`
+
+	// Aliases declares additional commands invoking builtin commands by name.
+	Aliases map[string]AliasConfig `yaml:"aliases"`
+
+	// Labels configures label commands.
+	Labels LabelConfig `yaml:"labels"`
+
+	// Trigger determines how commands are written in comments (see [parser.ParseTrigger]).
+	Trigger string `yaml:"trigger"`
+}
+
+// CommandConfig customizes a builtin command.
+type CommandConfig struct {
+	// Aliases are added to the aliases of the command.
+	Aliases []string `yaml:"aliases"`
+
+	// Short overrides the help text of the command.
+	Short string `yaml:"short"`
+}
+
+// AliasConfig declares a command invoking a builtin command.
+//
+// Aliases are authorized as the builtin command they invoke.
+type AliasConfig struct {
+	// Command is the name of the builtin command to invoke.
+	Command string `yaml:"command"`
+
+	// Args are passed to the builtin command before the arguments of the alias.
+	Args []string `yaml:"args"`
+
+	// Prefix is prepended to every argument of the alias (eg. "kind/" turns "/kind bug" into "kind/bug").
+	Prefix string `yaml:"prefix"`
+
+	// Short is the help text of the alias.
+	Short string `yaml:"short"`
+}
+
+// LabelConfig configures label commands.
+type LabelConfig struct {
+	// Strict refuses to add labels that do not exist in the repository.
+	Strict bool `yaml:"strict"`
+
+	// Scopes configures label families (see [LabelScope]).
+	Scopes []LabelScopeConfig `yaml:"scopes"`
+}
+
+// LabelScopeConfig is the configuration counterpart of [LabelScope].
+type LabelScopeConfig struct {
+	Prefix    string `yaml:"prefix"`
+	Exclusive bool   `yaml:"exclusive"`
+	Command   string `yaml:"command"`
+	Short     string `yaml:"short"`
+}
+
+// LoadConfig loads the builtin command configuration from [ConfigFileName] in fsys.
+//
+// A missing file results in an empty configuration.
+func LoadConfig(fsys fs.FS) (Config, error) {
+	var config Config
+
+	if fsys == nil {
+		return config, nil
+	}
+
+	b, err := fs.ReadFile(fsys, ConfigFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	err = yaml.Unmarshal(b, &config)
+	if err != nil {
+		return config, fmt.Errorf("decoding %s: %w", ConfigFileName, err)
+	}
+
+	return config, nil
+}
+
+// trigger returns the trigger declared in the configuration (or fallback if it is not set).
+func (c Config) trigger(fallback parser.Trigger) (parser.Trigger, error) {
+	if c.Trigger == "" {
+		return fallback, nil
+	}
+
+	return parser.ParseTrigger(c.Trigger)
+}
+
+// labelScopes returns the label scopes declared in the configuration.
+func (c Config) labelScopes() []LabelScope {
+	scopes := make([]LabelScope, 0, len(c.Labels.Scopes))
+
+	for _, scope := range c.Labels.Scopes {
+		scopes = append(scopes, LabelScope{
+			Prefix:    scope.Prefix,
+			Exclusive: scope.Exclusive,
+			Command:   scope.Command,
+			Short:     scope.Short,
+		})
+	}
+
+	return scopes
+}
+
+// apply customizes the builtin command tree.
+func (c Config) apply(rootCmd *cobra.Command) error {
+	var errs []error
+
+	for _, name := range c.Disable {
+		cmd := findCommand(rootCmd, name)
+		if cmd == nil {
+			errs = append(errs, fmt.Errorf("disable: unknown command %q", name))
+
+			continue
+		}
+
+		rootCmd.RemoveCommand(cmd)
+	}
+
+	// Iterate in a stable order, so errors (and conflicts between aliases) are deterministic
+	for _, name := range slices.Sorted(maps.Keys(c.Commands)) {
+		config := c.Commands[name]
+
+		cmd := findCommand(rootCmd, name)
+		if cmd == nil {
+			errs = append(errs, fmt.Errorf("commands: unknown (or disabled) command %q", name))
+
+			continue
+		}
+
+		for _, alias := range config.Aliases {
+			if other := findCommand(rootCmd, alias); other != nil && other != cmd {
+				errs = append(errs, fmt.Errorf("commands: %s: alias %q conflicts with an existing command", name, alias))
+
+				continue
+			}
+
+			cmd.Aliases = append(cmd.Aliases, alias)
+		}
+
+		if config.Short != "" {
+			cmd.Short = config.Short
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Aliases)) {
+		config := c.Aliases[name]
+
+		target := findCommand(rootCmd, config.Command)
+		if target == nil {
+			errs = append(errs, fmt.Errorf("aliases: %s: unknown (or disabled) command %q", name, config.Command))
+
+			continue
+		}
+
+		if findCommand(rootCmd, name) != nil {
+			errs = append(errs, fmt.Errorf("aliases: %s: conflicts with an existing command", name))
+
+			continue
+		}
+
+		rootCmd.AddCommand(command.NewAliasCommand(name, target, command.Alias{
+			Args:   config.Args,
+			Prefix: config.Prefix,
+			Short:  config.Short,
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// findCommand finds a direct subcommand by name or alias.
+func findCommand(parent *cobra.Command, name string) *cobra.Command {
+	for _, cmd := range parent.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return cmd
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package builtin
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+func TestConfig_Apply(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		check  func(t *testing.T, rootCmd *cobra.Command)
+		err    string
+	}{
+		{
+			name:   "disable",
+			config: Config{Disable: []string{"lock", "unlock"}},
+			check: func(t *testing.T, rootCmd *cobra.Command) {
+				if findCommand(rootCmd, "lock") != nil || findCommand(rootCmd, "unlock") != nil {
+					t.Error("expected lock and unlock to be disabled")
+				}
+			},
+		},
+		{
+			name: "customize",
+			config: Config{
+				Commands: map[string]CommandConfig{
+					"close": {Aliases: []string{"done"}, Short: "Done"},
+				},
+			},
+			check: func(t *testing.T, rootCmd *cobra.Command) {
+				cmd := findCommand(rootCmd, "done")
+				if cmd == nil || cmd.Name() != "close" || cmd.Short != "Done" {
+					t.Errorf("expected done to be an alias of close, got %v", cmd)
+				}
+			},
+		},
+		{
+			name: "command alias conflicting with a command",
+			config: Config{
+				Commands: map[string]CommandConfig{
+					"close": {Aliases: []string{"done", "reopen"}},
+				},
+			},
+			err: `commands: close: alias "reopen" conflicts with an existing command`,
+		},
+		{
+			name: "command aliases conflicting with each other",
+			config: Config{
+				Commands: map[string]CommandConfig{
+					"close":  {Aliases: []string{"x"}},
+					"reopen": {Aliases: []string{"x"}},
+				},
+			},
+			err: `commands: reopen: alias "x" conflicts with an existing command`,
+		},
+		{
+			name: "alias",
+			config: Config{
+				Aliases: map[string]AliasConfig{
+					"kind": {Command: "add-label", Prefix: "kind/"},
+				},
+			},
+			check: func(t *testing.T, rootCmd *cobra.Command) {
+				cmd := findCommand(rootCmd, "kind")
+				if cmd == nil || cmd.Annotations[command.ActionAnnotation] != "add-label" {
+					t.Errorf("expected kind to be authorized as add-label, got %v", cmd)
+				}
+			},
+		},
+		{
+			name: "alias of disabled command",
+			config: Config{
+				Disable: []string{"add-label"},
+				Aliases: map[string]AliasConfig{
+					"kind": {Command: "add-label", Prefix: "kind/"},
+				},
+			},
+			err: `aliases: kind: unknown (or disabled) command "add-label"`,
+		},
+		{
+			name: "errors are sorted",
+			config: Config{
+				Disable: []string{"nope"},
+				Commands: map[string]CommandConfig{
+					"zzz": {},
+					"aaa": {},
+				},
+				Aliases: map[string]AliasConfig{
+					"close": {Command: "reopen"},
+					"bug":   {Command: "nope"},
+				},
+			},
+			err: `disable: unknown command "nope"
+commands: unknown (or disabled) command "aaa"
+commands: unknown (or disabled) command "zzz"
+aliases: bug: unknown (or disabled) command "nope"
+aliases: close: conflicts with an existing command`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			provider := CommandProvider{}
+			rootCmd, err := provider.newCommand(github.IssueCommentEvent{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = testCase.config.apply(rootCmd)
+			if testCase.err != "" {
+				if err == nil || err.Error() != testCase.err {
+					t.Fatalf("expected error %q, got %v", testCase.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testCase.check(t, rootCmd)
+		})
+	}
+}
+
+func TestConfig_LabelScopes(t *testing.T) {
+	config := Config{
+		Labels: LabelConfig{
+			Scopes: []LabelScopeConfig{
+				{Prefix: "priority/", Exclusive: true, Command: "priority", Short: "Set priority"},
+			},
+		},
+	}
+
+	expected := []LabelScope{
+		{Prefix: "priority/", Exclusive: true, Command: "priority", Short: "Set priority"},
+	}
+
+	if actual := config.labelScopes(); !slices.Equal(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
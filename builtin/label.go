@@ -2,23 +2,36 @@ package builtin
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/google/go-github/v74/github"
 	"github.com/spf13/cobra"
 )
 
-// AddLabel represents a command to add a label to an issue or pull request.
+// AddLabel represents a command to add labels to an issue or pull request.
 type AddLabel struct {
-	Repo  *github.Repository
-	Issue *github.Issue
-	Label string
+	Repo   *github.Repository
+	Issue  *github.Issue
+	Labels []string
 }
 
 // AddLabelHandler handles the [AddLabel] command.
 type AddLabelHandler struct {
 	Client *github.Client
 	Logger *slog.Logger
+
+	// StrictLabels refuses to add labels that do not exist in the repository
+	// (instead of letting GitHub create them).
+	StrictLabels bool
+
+	// Scopes configures label families.
+	// Adding a label from an exclusive scope removes other labels from the same scope.
+	Scopes []LabelScope
 }
 
 // Handle executes the [AddLabel] command.
@@ -28,23 +41,100 @@ func (h AddLabelHandler) Handle(ctx context.Context, cmd AddLabel) error {
 
 	logger := h.Logger.With(slog.Int("number", issue.GetNumber()))
 
-	logger.Info("adding label to issue", slog.String("label", cmd.Label))
+	labels, err := h.resolveLabels(ctx, repo, cmd.Labels)
+	if err != nil {
+		return err
+	}
 
-	_, _, err := h.Client.Issues.AddLabelsToIssue(
+	// Check for conflicts before adding any labels
+	_, err = exclusiveLabelsToRemove(h.Scopes, nil, labels)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("adding labels to issue", slog.Any("labels", labels))
+
+	current, _, err := h.Client.Issues.AddLabelsToIssue(
 		ctx,
 		repo.GetOwner().GetLogin(),
 		repo.GetName(),
 		issue.GetNumber(),
-		[]string{cmd.Label},
+		labels,
 	)
 	if err != nil {
 		return err
 	}
 
+	// Use the labels returned by GitHub: labels in the event may be outdated
+	// (eg. if a previous command changed them)
+	remove, err := exclusiveLabelsToRemove(h.Scopes, current, labels)
+	if err != nil {
+		return err
+	}
+
+	// Remove conflicting labels after adding the new ones,
+	// so the issue is never left without a label from the scope.
+	for _, label := range remove {
+		logger.Info("removing label from exclusive scope", slog.String("label", label))
+
+		_, err := h.Client.Issues.RemoveLabelForIssue(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			issue.GetNumber(),
+			label,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// NewAddLabelCommand creates a new Cobra command to add a label to an issue or pull request.
+// resolveLabels validates labels against the labels of the repository (if strict labels are enabled)
+// and returns them with the name used by the repository.
+//
+// Otherwise labels are returned as is: GitHub matches them against existing labels case-insensitively
+// and creates the missing ones.
+func (h AddLabelHandler) resolveLabels(
+	ctx context.Context,
+	repo *github.Repository,
+	labels []string,
+) ([]string, error) {
+	if !h.StrictLabels {
+		return labels, nil
+	}
+
+	repoLabels, err := listLabels(ctx, h.Client, repo)
+	if err != nil {
+		return nil, fmt.Errorf("listing repository labels: %w", err)
+	}
+
+	resolved := make([]string, 0, len(labels))
+
+	var unknown []string
+
+	for _, label := range labels {
+		name, ok := findLabel(repoLabels, label)
+		if !ok {
+			unknown = append(unknown, label)
+		}
+
+		resolved = append(resolved, name)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf(
+			"labels do not exist in the repository: %s",
+			strings.Join(unknown, ", "),
+		)
+	}
+
+	return resolved, nil
+}
+
+// NewAddLabelCommand creates a new Cobra command to add labels to an issue or pull request.
 //
 // It integrates the [AddLabel] command into the default command dispatcher.
 func NewAddLabelCommand(
@@ -57,6 +147,17 @@ func NewAddLabelCommand(
 		Logger: logger,
 	}
 
+	return NewAddLabelCommandWithHandler(event, handler)
+}
+
+// NewAddLabelCommandWithHandler creates a new Cobra command to add labels to an issue or pull request
+// using a preconfigured handler (eg. with strict labels or label scopes).
+//
+// It integrates the [AddLabel] command into the default command dispatcher.
+func NewAddLabelCommandWithHandler(
+	event github.IssueCommentEvent,
+	handler AddLabelHandler,
+) *cobra.Command {
 	return newAddLabelCommand(event, handler)
 }
 
@@ -68,12 +169,12 @@ func newAddLabelCommand(
 		Use:     "add-label",
 		Aliases: []string{"label"},
 		Short:   "Label an issue or pull request",
-		Args:    cobra.ExactArgs(1),
+		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			command := AddLabel{
-				Repo:  event.GetRepo(),
-				Issue: event.GetIssue(),
-				Label: args[0],
+				Repo:   event.GetRepo(),
+				Issue:  event.GetIssue(),
+				Labels: args,
 			}
 
 			return handler.Handle(cmd.Context(), command)
@@ -83,11 +184,17 @@ func newAddLabelCommand(
 	return cmd
 }
 
-// RemoveLabel represents a command to remove a label from an issue or pull request.
+// RemoveLabel represents a command to remove labels from an issue or pull request.
 type RemoveLabel struct {
 	Repo  *github.Repository
 	Issue *github.Issue
-	Label string
+
+	// Labels to remove.
+	//
+	// Labels may contain glob patterns that are matched against the current labels of the issue:
+	// * matches any sequence of characters (including /), ? matches any single character
+	// and [...] matches a character class. Patterns are matched case-insensitively.
+	Labels []string
 }
 
 // RemoveLabelHandler handles the [RemoveLabel] command.
@@ -103,23 +210,49 @@ func (h RemoveLabelHandler) Handle(ctx context.Context, cmd RemoveLabel) error {
 
 	logger := h.Logger.With(slog.Int("number", issue.GetNumber()))
 
-	logger.Info("removing label from issue", slog.String("label", cmd.Label))
+	var current []*github.Label
 
-	_, err := h.Client.Issues.RemoveLabelForIssue(
-		ctx,
-		repo.GetOwner().GetLogin(),
-		repo.GetName(),
-		issue.GetNumber(),
-		cmd.Label,
-	)
+	// Only look up the labels of the issue when they are needed:
+	// labels in the event may be outdated (eg. if a previous command changed them).
+	if slices.ContainsFunc(cmd.Labels, isGlob) {
+		var err error
+
+		current, err = listIssueLabels(ctx, h.Client, repo, issue)
+		if err != nil {
+			return fmt.Errorf("listing issue labels: %w", err)
+		}
+	}
+
+	labels, err := matchLabels(current, cmd.Labels)
 	if err != nil {
 		return err
 	}
 
+	if len(labels) == 0 {
+		logger.Info("no labels to remove from issue", slog.Any("patterns", cmd.Labels))
+
+		return nil
+	}
+
+	for _, label := range labels {
+		logger.Info("removing label from issue", slog.String("label", label))
+
+		_, err := h.Client.Issues.RemoveLabelForIssue(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			issue.GetNumber(),
+			label,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// NewRemoveLabelCommand creates a new Cobra command to remove a label from an issue or pull request.
+// NewRemoveLabelCommand creates a new Cobra command to remove labels from an issue or pull request.
 //
 // It integrates the [RemoveLabel] command into the default command dispatcher.
 func NewRemoveLabelCommand(
@@ -141,13 +274,13 @@ func newRemoveLabelCommand(
 ) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "remove-label",
-		Short: "Remove a label from an issue or pull request",
-		Args:  cobra.ExactArgs(1),
+		Short: "Remove labels from an issue or pull request",
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			command := RemoveLabel{
-				Repo:  event.GetRepo(),
-				Issue: event.GetIssue(),
-				Label: args[0],
+				Repo:   event.GetRepo(),
+				Issue:  event.GetIssue(),
+				Labels: args,
 			}
 
 			return handler.Handle(cmd.Context(), command)
@@ -156,3 +289,177 @@ func newRemoveLabelCommand(
 
 	return cmd
 }
+
+// listLabels lists all labels in a repository.
+func listLabels(
+	ctx context.Context,
+	client *github.Client,
+	repo *github.Repository,
+) ([]*github.Label, error) {
+	var labels []*github.Label
+
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+		page, resp, err := client.Issues.ListLabels(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return labels, nil
+}
+
+// listIssueLabels lists the labels of an issue or pull request.
+func listIssueLabels(
+	ctx context.Context,
+	client *github.Client,
+	repo *github.Repository,
+	issue *github.Issue,
+) ([]*github.Label, error) {
+	var labels []*github.Label
+
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+		page, resp, err := client.Issues.ListLabelsByIssue(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			issue.GetNumber(),
+			opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return labels, nil
+}
+
+// findLabel looks up a label by name (case-insensitively, the same way GitHub does).
+func findLabel(labels []*github.Label, name string) (string, bool) {
+	for _, label := range labels {
+		if strings.EqualFold(label.GetName(), name) {
+			return label.GetName(), true
+		}
+	}
+
+	return "", false
+}
+
+// matchLabels returns the labels matching any of the patterns.
+//
+// Patterns without glob characters are returned as is.
+func matchLabels(labels []*github.Label, patterns []string) ([]string, error) {
+	var matches []string
+
+	seen := make(map[string]bool)
+
+	add := func(label string) {
+		if seen[label] {
+			return
+		}
+
+		seen[label] = true
+		matches = append(matches, label)
+	}
+
+	for _, pattern := range patterns {
+		if !isGlob(pattern) {
+			add(pattern)
+
+			continue
+		}
+
+		match, err := compileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+		}
+
+		for _, label := range labels {
+			if match.MatchString(label.GetName()) {
+				add(label.GetName())
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// compileGlob converts a glob pattern to a regular expression.
+//
+// Unlike [path.Match], * matches / as well, since it has no special meaning in label names.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	b.WriteString("(?i)^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+
+		case '?':
+			b.WriteString(".")
+
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+
+			i += end + 1
+
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
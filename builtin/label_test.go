@@ -0,0 +1,87 @@
+package builtin
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestMatchLabels(t *testing.T) {
+	labels := []*github.Label{
+		{Name: github.Ptr("area/parser")},
+		{Name: github.Ptr("area/parser/heredoc")},
+		{Name: github.Ptr("Bug")},
+		{Name: github.Ptr("priority/high")},
+		{Name: github.Ptr("priority/low")},
+	}
+
+	testCases := []struct {
+		name     string
+		patterns []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "literal",
+			patterns: []string{"needs review"},
+			expected: []string{"needs review"},
+		},
+		{
+			name:     "star crosses slashes",
+			patterns: []string{"area/*"},
+			expected: []string{"area/parser", "area/parser/heredoc"},
+		},
+		{
+			name:     "question mark",
+			patterns: []string{"priority/lo?"},
+			expected: []string{"priority/low"},
+		},
+		{
+			name:     "character class",
+			patterns: []string{"priority/[!l]*"},
+			expected: []string{"priority/high"},
+		},
+		{
+			name:     "case-insensitive",
+			patterns: []string{"b*"},
+			expected: []string{"Bug"},
+		},
+		{
+			name:     "escaped",
+			patterns: []string{`area\*`},
+			expected: nil,
+		},
+		{
+			name:     "deduplicated",
+			patterns: []string{"priority/*", "*/high"},
+			expected: []string{"priority/high", "priority/low"},
+		},
+		{
+			name:     "invalid",
+			patterns: []string{"priority/[high"},
+			wantErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := matchLabels(labels, testCase.patterns)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(actual, testCase.expected) {
+				t.Errorf("expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
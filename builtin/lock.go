@@ -0,0 +1,179 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+)
+
+// LockReasons lists the lock reasons accepted by GitHub.
+var LockReasons = []string{"off-topic", "too heated", "resolved", "spam"}
+
+// Lock represents a command to lock the conversation on an issue or pull request.
+type Lock struct {
+	Repo   *github.Repository
+	Issue  *github.Issue
+	Reason string
+}
+
+// LockHandler handles the [Lock] command.
+type LockHandler struct {
+	Client *github.Client
+	Logger *slog.Logger
+}
+
+// Handle executes the [Lock] command.
+func (h LockHandler) Handle(ctx context.Context, cmd Lock) error {
+	issue := cmd.Issue
+	repo := cmd.Repo
+
+	h.Logger.Info(
+		"locking issue",
+		slog.Int("number", issue.GetNumber()),
+		slog.String("reason", cmd.Reason),
+	)
+
+	var opts *github.LockIssueOptions
+
+	if cmd.Reason != "" {
+		opts = &github.LockIssueOptions{
+			LockReason: cmd.Reason,
+		}
+	}
+
+	_, err := h.Client.Issues.Lock(
+		ctx,
+		repo.GetOwner().GetLogin(),
+		repo.GetName(),
+		issue.GetNumber(),
+		opts,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewLockCommand creates a new Cobra command to lock the conversation on an issue or pull request.
+//
+// It integrates the [Lock] command into the default command dispatcher.
+func NewLockCommand(
+	event github.IssueCommentEvent,
+	client *github.Client,
+	logger *slog.Logger,
+) *cobra.Command {
+	handler := LockHandler{
+		Client: client,
+		Logger: logger,
+	}
+
+	return newLockCommand(event, handler)
+}
+
+func newLockCommand(
+	event github.IssueCommentEvent,
+	handler commandHandler[Lock],
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "lock",
+		Short:     "Lock the conversation on an issue or pull request",
+		ValidArgs: LockReasons,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Allow "too heated" to be passed without quotes
+			reason := strings.Join(args, " ")
+
+			if reason != "" && !slices.Contains(LockReasons, reason) {
+				return fmt.Errorf(
+					"invalid lock reason %q (valid reasons: %s)",
+					reason,
+					strings.Join(LockReasons, ", "),
+				)
+			}
+
+			command := Lock{
+				Repo:   event.GetRepo(),
+				Issue:  event.GetIssue(),
+				Reason: reason,
+			}
+
+			return handler.Handle(cmd.Context(), command)
+		},
+	}
+
+	return cmd
+}
+
+// Unlock represents a command to unlock the conversation on an issue or pull request.
+type Unlock struct {
+	Repo  *github.Repository
+	Issue *github.Issue
+}
+
+// UnlockHandler handles the [Unlock] command.
+type UnlockHandler struct {
+	Client *github.Client
+	Logger *slog.Logger
+}
+
+// Handle executes the [Unlock] command.
+func (h UnlockHandler) Handle(ctx context.Context, cmd Unlock) error {
+	issue := cmd.Issue
+	repo := cmd.Repo
+
+	h.Logger.Info("unlocking issue", slog.Int("number", issue.GetNumber()))
+
+	_, err := h.Client.Issues.Unlock(
+		ctx,
+		repo.GetOwner().GetLogin(),
+		repo.GetName(),
+		issue.GetNumber(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewUnlockCommand creates a new Cobra command to unlock the conversation on an issue or pull request.
+//
+// It integrates the [Unlock] command into the default command dispatcher.
+func NewUnlockCommand(
+	event github.IssueCommentEvent,
+	client *github.Client,
+	logger *slog.Logger,
+) *cobra.Command {
+	handler := UnlockHandler{
+		Client: client,
+		Logger: logger,
+	}
+
+	return newUnlockCommand(event, handler)
+}
+
+func newUnlockCommand(
+	event github.IssueCommentEvent,
+	handler commandHandler[Unlock],
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Unlock the conversation on an issue or pull request",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			command := Unlock{
+				Repo:  event.GetRepo(),
+				Issue: event.GetIssue(),
+			}
+
+			return handler.Handle(cmd.Context(), command)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,203 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// request is a request received by a test GitHub API server.
+type request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// newTestClient returns a GitHub client sending requests to a test server that records them.
+func newTestClient(t *testing.T) (*github.Client, *[]request) {
+	t.Helper()
+
+	var requests []request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		requests = append(requests, request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Body:   strings.TrimSpace(string(body)),
+		})
+
+		if r.Method == http.MethodPut || r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return client, &requests
+}
+
+func newTestEvent() github.IssueCommentEvent {
+	return github.IssueCommentEvent{
+		Repo: &github.Repository{
+			Owner: &github.User{Login: github.Ptr("owner")},
+			Name:  github.Ptr("repo"),
+		},
+		Issue: &github.Issue{
+			Number: github.Ptr(12),
+		},
+	}
+}
+
+func assertRequests(t *testing.T, actual []request, expected ...request) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d requests, got %d: %+v", len(expected), len(actual), actual)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected request %+v, got %+v", expected[i], actual[i])
+		}
+	}
+}
+
+func TestReopenCommand(t *testing.T) {
+	client, requests := newTestClient(t)
+	logger := slog.New(slog.DiscardHandler)
+
+	cmd := NewReopenCommand(newTestEvent(), client, logger)
+	cmd.SetArgs([]string{})
+
+	err := cmd.ExecuteContext(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertRequests(t, *requests, request{
+		Method: http.MethodPatch,
+		Path:   "/repos/owner/repo/issues/12",
+		Body:   `{"state":"open"}`,
+	})
+}
+
+func TestLockCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no reason",
+			args:     []string{},
+			expected: "null",
+		},
+		{
+			name:     "reason",
+			args:     []string{"spam"},
+			expected: `{"lock_reason":"spam"}`,
+		},
+		{
+			name:     "unquoted reason with spaces",
+			args:     []string{"too", "heated"},
+			expected: `{"lock_reason":"too heated"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client, requests := newTestClient(t)
+			logger := slog.New(slog.DiscardHandler)
+
+			cmd := NewLockCommand(newTestEvent(), client, logger)
+			cmd.SetArgs(testCase.args)
+
+			err := cmd.ExecuteContext(t.Context())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertRequests(t, *requests, request{
+				Method: http.MethodPut,
+				Path:   "/repos/owner/repo/issues/12/lock",
+				Body:   testCase.expected,
+			})
+		})
+	}
+}
+
+type lockHandlerFunc func(ctx context.Context, cmd Lock) error
+
+func (f lockHandlerFunc) Handle(ctx context.Context, cmd Lock) error {
+	return f(ctx, cmd)
+}
+
+func TestLockCommand_InvalidReason(t *testing.T) {
+	cmd := newLockCommand(newTestEvent(), lockHandlerFunc(func(context.Context, Lock) error {
+		return errors.New("handler should not be called")
+	}))
+	cmd.SetArgs([]string{"boring"})
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	err := cmd.ExecuteContext(t.Context())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), `invalid lock reason "boring"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUnlockCommand(t *testing.T) {
+	client, requests := newTestClient(t)
+	logger := slog.New(slog.DiscardHandler)
+
+	cmd := NewUnlockCommand(newTestEvent(), client, logger)
+	cmd.SetArgs([]string{})
+
+	err := cmd.ExecuteContext(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertRequests(t, *requests, request{
+		Method: http.MethodDelete,
+		Path:   "/repos/owner/repo/issues/12/lock",
+	})
+}
+
+func TestUnlockCommand_Args(t *testing.T) {
+	client, requests := newTestClient(t)
+	logger := slog.New(slog.DiscardHandler)
+
+	cmd := NewUnlockCommand(newTestEvent(), client, logger)
+	cmd.SetArgs([]string{"now"})
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	err := cmd.ExecuteContext(t.Context())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	assertRequests(t, *requests)
+}
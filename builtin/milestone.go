@@ -0,0 +1,265 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+// Milestone represents a command to set (or clear) the milestone of an issue or pull request.
+type Milestone struct {
+	Repo  *github.Repository
+	Issue *github.Issue
+
+	// Milestone is the milestone to set.
+	// A nil value clears the milestone.
+	Milestone *github.Milestone
+}
+
+// MilestoneHandler handles the [Milestone] command.
+type MilestoneHandler struct {
+	Client *github.Client
+	Logger *slog.Logger
+}
+
+// Handle executes the [Milestone] command.
+func (h MilestoneHandler) Handle(ctx context.Context, cmd Milestone) error {
+	repo := cmd.Repo
+	issue := cmd.Issue
+
+	logger := h.Logger.With(slog.Int("number", issue.GetNumber()))
+
+	if cmd.Milestone == nil {
+		logger.Info("clearing milestone")
+
+		_, _, err := h.Client.Issues.RemoveMilestone(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			issue.GetNumber(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	logger.Info("setting milestone", slog.String("milestone", cmd.Milestone.GetTitle()))
+
+	_, _, err := h.Client.Issues.Edit(
+		ctx,
+		repo.GetOwner().GetLogin(),
+		repo.GetName(),
+		issue.GetNumber(),
+		&github.IssueRequest{
+			Milestone: github.Ptr(cmd.Milestone.GetNumber()),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResolveMilestone finds an open milestone in a repository.
+//
+// The query matches a milestone by exact title, number (optionally prefixed with #)
+// or unique, case-insensitive title prefix (in that order).
+func (h MilestoneHandler) ResolveMilestone(
+	ctx context.Context,
+	repo *github.Repository,
+	query string,
+) (*github.Milestone, error) {
+	milestones, err := h.ListMilestones(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchMilestone(milestones, query)
+}
+
+// ListMilestones lists the open milestones of a repository.
+func (h MilestoneHandler) ListMilestones(
+	ctx context.Context,
+	repo *github.Repository,
+) ([]*github.Milestone, error) {
+	var milestones []*github.Milestone
+
+	opts := &github.MilestoneListOptions{
+		State: "open",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		page, resp, err := h.Client.Issues.ListMilestones(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		milestones = append(milestones, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return milestones, nil
+}
+
+func matchMilestone(milestones []*github.Milestone, query string) (*github.Milestone, error) {
+	for _, milestone := range milestones {
+		if milestone.GetTitle() == query {
+			return milestone, nil
+		}
+	}
+
+	if number, err := strconv.Atoi(strings.TrimPrefix(query, "#")); err == nil {
+		for _, milestone := range milestones {
+			if milestone.GetNumber() == number {
+				return milestone, nil
+			}
+		}
+	}
+
+	var candidates []*github.Milestone
+
+	for _, milestone := range milestones {
+		if strings.HasPrefix(strings.ToLower(milestone.GetTitle()), strings.ToLower(query)) {
+			candidates = append(candidates, milestone)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("no open milestone matches %q", query)
+
+	case 1:
+		return candidates[0], nil
+
+	default:
+		titles := make([]string, 0, len(candidates))
+
+		for _, milestone := range candidates {
+			titles = append(titles, strconv.Quote(milestone.GetTitle()))
+		}
+
+		return nil, fmt.Errorf(
+			"milestone %q is ambiguous (candidates: %s)",
+			query,
+			strings.Join(titles, ", "),
+		)
+	}
+}
+
+// NewMilestoneCommand creates a new Cobra command to set the milestone of an issue or pull request.
+//
+// It integrates the [Milestone] command into the default command dispatcher.
+func NewMilestoneCommand(
+	event github.IssueCommentEvent,
+	client *github.Client,
+	logger *slog.Logger,
+) *cobra.Command {
+	handler := MilestoneHandler{
+		Client: client,
+		Logger: logger,
+	}
+
+	return newMilestoneCommand(event, handler, handler)
+}
+
+type milestoneLister interface {
+	ListMilestones(ctx context.Context, repo *github.Repository) ([]*github.Milestone, error)
+}
+
+func newMilestoneCommand(
+	event github.IssueCommentEvent,
+	lister milestoneLister,
+	handler commandHandler[Milestone],
+) *cobra.Command {
+	var (
+		clearMilestone bool
+		milestone      *github.Milestone
+	)
+
+	cmd := &cobra.Command{
+		Use:   "milestone",
+		Short: "Set (or clear) the milestone of an issue or pull request",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if clearMilestone {
+				return cobra.NoArgs(cmd, args)
+			}
+
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+
+		// Resolve the milestone after authorizing the command (so unauthorized users cannot trigger lookups),
+		// then authorize it again, so policies can decide based on its title
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Allow titles with spaces to be passed without quotes
+			query := strings.Join(args, " ")
+
+			if !clearMilestone {
+				milestones, err := lister.ListMilestones(cmd.Context(), event.GetRepo())
+				if err != nil {
+					return err
+				}
+
+				titled := func(m *github.Milestone) bool { return m.GetTitle() == query }
+
+				// "clear" clears the milestone, unless a milestone is titled exactly "clear"
+				if query == "clear" && !slices.ContainsFunc(milestones, titled) {
+					clearMilestone = true
+				} else {
+					milestone, err = matchMilestone(milestones, query)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			if clearMilestone {
+				return command.Authorize(cmd.Context(), map[string]any{
+					"milestone": event.GetIssue().GetMilestone().GetTitle(),
+					"clear":     true,
+				})
+			}
+
+			return command.Authorize(cmd.Context(), map[string]any{
+				"milestone": milestone.GetTitle(),
+				"clear":     false,
+			})
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			command := Milestone{
+				Repo:      event.GetRepo(),
+				Issue:     event.GetIssue(),
+				Milestone: milestone,
+			}
+
+			return handler.Handle(cmd.Context(), command)
+		},
+	}
+
+	cmd.Flags().BoolVar(&clearMilestone, "clear", false, "Clear the milestone")
+
+	return cmd
+}
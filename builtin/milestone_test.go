@@ -0,0 +1,107 @@
+package builtin_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+func TestMilestoneCommand(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		milestones []octoslashtest.Milestone
+		authorizer command.Authorizer
+		status     octoslash.ResultStatus
+		milestone  int
+	}{
+		{
+			name:      "set",
+			body:      "/milestone v2",
+			status:    octoslash.ResultSucceeded,
+			milestone: 2,
+		},
+		{
+			name:      "clear",
+			body:      "/milestone clear",
+			status:    octoslash.ResultSucceeded,
+			milestone: 0,
+		},
+		{
+			name:      "clear flag",
+			body:      "/milestone --clear",
+			status:    octoslash.ResultSucceeded,
+			milestone: 0,
+		},
+		{
+			name:       "milestone titled clear",
+			body:       "/milestone clear",
+			milestones: []octoslashtest.Milestone{{Number: 3, Title: "clear"}},
+			status:     octoslash.ResultSucceeded,
+			milestone:  3,
+		},
+		{
+			name:       "clear flag with milestone titled clear",
+			body:       "/milestone --clear",
+			milestones: []octoslashtest.Milestone{{Number: 3, Title: "clear"}},
+			status:     octoslash.ResultSucceeded,
+			milestone:  0,
+		},
+		{
+			name:       "denied",
+			body:       "/milestone v2",
+			authorizer: octoslashtest.Deny("milestone"),
+			status:     octoslash.ResultDenied,
+			milestone:  1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+
+			repo := newRepository()
+			repo.Milestones = append(repo.Milestones, testCase.milestones...)
+			repo.Issues[1].Milestone = 1
+			server.AddRepository(repo)
+
+			authorizer := testCase.authorizer
+			if authorizer == nil {
+				authorizer = octoslashtest.AllowAll()
+			}
+
+			handler := octoslash.EventHandler{
+				Dispatcher: command.CobraDispatcher{
+					Authorizer: authorizer,
+					CommandProvider: builtin.CommandProvider{
+						Client: server.Client(),
+						Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+					},
+				},
+			}
+
+			event := octoslashtest.NewIssueCommentEvent(
+				testCase.body,
+				octoslashtest.WithIssue(repo.Issues[1]),
+			)
+
+			results, _ := handler.Execute(t.Context(), event)
+
+			octoslashtest.AssertResults(t, results, testCase.status)
+
+			if milestone := repo.Issues[1].Milestone; milestone != testCase.milestone {
+				t.Errorf("expected milestone %d, got %d", testCase.milestone, milestone)
+			}
+
+			// Unauthorized users must not be able to trigger lookups
+			if testCase.status == octoslash.ResultDenied && len(server.Requests()) > 0 {
+				t.Errorf("expected no requests, got %v", server.Requests())
+			}
+		})
+	}
+}
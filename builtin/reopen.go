@@ -0,0 +1,81 @@
+package builtin
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+)
+
+// Reopen represents a command to reopen a closed issue or pull request.
+type Reopen struct {
+	Repo  *github.Repository
+	Issue *github.Issue
+}
+
+// ReopenHandler handles the [Reopen] command.
+type ReopenHandler struct {
+	Client *github.Client
+	Logger *slog.Logger
+}
+
+// Handle executes the [Reopen] command.
+func (h ReopenHandler) Handle(ctx context.Context, cmd Reopen) error {
+	issue := cmd.Issue
+	repo := cmd.Repo
+
+	h.Logger.Info("reopening issue", slog.Int("number", issue.GetNumber()))
+
+	_, _, err := h.Client.Issues.Edit(
+		ctx,
+		repo.GetOwner().GetLogin(),
+		repo.GetName(),
+		issue.GetNumber(),
+		&github.IssueRequest{
+			State: github.Ptr("open"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewReopenCommand creates a new Cobra command to reopen a closed issue or pull request.
+//
+// It integrates the [Reopen] command into the default command dispatcher.
+func NewReopenCommand(
+	event github.IssueCommentEvent,
+	client *github.Client,
+	logger *slog.Logger,
+) *cobra.Command {
+	handler := ReopenHandler{
+		Client: client,
+		Logger: logger,
+	}
+
+	return newReopenCommand(event, handler)
+}
+
+func newReopenCommand(
+	event github.IssueCommentEvent,
+	handler commandHandler[Reopen],
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reopen",
+		Short: "Reopen a closed issue or pull request",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			command := Reopen{
+				Repo:  event.GetRepo(),
+				Issue: event.GetIssue(),
+			}
+
+			return handler.Handle(cmd.Context(), command)
+		},
+	}
+
+	return cmd
+}
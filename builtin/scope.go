@@ -0,0 +1,131 @@
+package builtin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+// LabelScope configures a family of labels sharing a common prefix (eg. priority/high, priority/low).
+type LabelScope struct {
+	// Prefix shared by labels in the scope (eg. "priority/").
+	Prefix string
+
+	// Exclusive scopes allow only one label at a time on an issue or pull request:
+	// adding a label from the scope removes any other label from the same scope.
+	Exclusive bool
+
+	// Command is the name of an optional shorthand command for adding labels from the scope
+	// (eg. "priority" for "/priority high").
+	Command string
+
+	// Short is the description of the shorthand command.
+	Short string
+}
+
+func (s LabelScope) contains(label string) bool {
+	return s.Prefix != "" && strings.HasPrefix(strings.ToLower(label), strings.ToLower(s.Prefix))
+}
+
+// exclusiveLabelsToRemove returns the current labels that conflict with the added labels in exclusive scopes.
+func exclusiveLabelsToRemove(
+	scopes []LabelScope,
+	current []*github.Label,
+	added []string,
+) ([]string, error) {
+	var remove []string
+
+	for _, scope := range scopes {
+		if !scope.Exclusive {
+			continue
+		}
+
+		var scoped []string
+
+		for _, label := range added {
+			if scope.contains(label) {
+				scoped = append(scoped, label)
+			}
+		}
+
+		switch len(scoped) {
+		case 0:
+			continue
+
+		case 1:
+
+		default:
+			return nil, fmt.Errorf(
+				"only one %s* label is allowed at a time (got: %s)",
+				scope.Prefix,
+				strings.Join(scoped, ", "),
+			)
+		}
+
+		for _, label := range current {
+			if scope.contains(label.GetName()) && !strings.EqualFold(label.GetName(), scoped[0]) {
+				remove = append(remove, label.GetName())
+			}
+		}
+	}
+
+	return remove, nil
+}
+
+// NewLabelScopeCommand creates a new Cobra command to add labels from a [LabelScope]
+// (eg. "/priority high" adds the "priority/high" label).
+//
+// It integrates the [AddLabel] command into the default command dispatcher.
+func NewLabelScopeCommand(
+	event github.IssueCommentEvent,
+	scope LabelScope,
+	handler AddLabelHandler,
+) *cobra.Command {
+	return newLabelScopeCommand(event, scope, handler)
+}
+
+func newLabelScopeCommand(
+	event github.IssueCommentEvent,
+	scope LabelScope,
+	handler commandHandler[AddLabel],
+) *cobra.Command {
+	short := scope.Short
+	if short == "" {
+		short = fmt.Sprintf("Add a %s* label to an issue or pull request", scope.Prefix)
+	}
+
+	args := cobra.MinimumNArgs(1)
+	if scope.Exclusive {
+		args = cobra.ExactArgs(1)
+	}
+
+	cmd := &cobra.Command{
+		Use:   scope.Command,
+		Short: short,
+		Args:  args,
+
+		// Shorthand commands are authorized the same way as adding labels directly
+		Annotations: map[string]string{
+			command.ActionAnnotation: "add-label",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			labels := make([]string, 0, len(args))
+
+			for _, arg := range args {
+				labels = append(labels, scope.Prefix+arg)
+			}
+
+			return handler.Handle(cmd.Context(), AddLabel{
+				Repo:   event.GetRepo(),
+				Issue:  event.GetIssue(),
+				Labels: labels,
+			})
+		},
+	}
+
+	return cmd
+}
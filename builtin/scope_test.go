@@ -0,0 +1,72 @@
+package builtin
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestExclusiveLabelsToRemove(t *testing.T) {
+	scopes := []LabelScope{
+		{Prefix: "priority/", Exclusive: true},
+		{Prefix: "area/"},
+	}
+
+	current := []*github.Label{
+		{Name: github.Ptr("priority/low")},
+		{Name: github.Ptr("area/parser")},
+		{Name: github.Ptr("bug")},
+	}
+
+	testCases := []struct {
+		name     string
+		added    []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "exclusive scope",
+			added:    []string{"priority/high"},
+			expected: []string{"priority/low"},
+		},
+		{
+			name:  "same label",
+			added: []string{"Priority/Low"},
+		},
+		{
+			name:  "non-exclusive scope",
+			added: []string{"area/cli"},
+		},
+		{
+			name:  "unscoped label",
+			added: []string{"enhancement"},
+		},
+		{
+			name:    "multiple labels from exclusive scope",
+			added:   []string{"priority/high", "priority/critical"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := exclusiveLabelsToRemove(scopes, current, testCase.added)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(actual, testCase.expected) {
+				t.Errorf("expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
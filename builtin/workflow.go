@@ -2,10 +2,13 @@ package builtin
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v74/github"
 	"github.com/spf13/cobra"
@@ -15,56 +18,393 @@ import (
 type WorkflowRun struct {
 	Repo             *github.Repository
 	Issue            *github.Issue
+	Comment          *github.IssueComment
 	WorkflowFileName string
 	Inputs           map[string]any
+
+	// Ref is the git reference to run the workflow on.
+	//
+	// Defaults to the head branch of the pull request.
+	// Setting a ref allows running workflows from issues as well.
+	Ref string
+
+	// Watch waits for the workflow run to complete and reports its conclusion.
+	Watch bool
+
+	// CorrelationInput is the name of a workflow input that receives a unique ID of the dispatch.
+	//
+	// The workflow must include the input in its run name (run-name) to tell the dispatched run apart from concurrent runs.
+	// Without a correlation input, the dispatched run is the only new run of the workflow on the ref after the dispatch,
+	// and the command fails if concurrent dispatches make it ambiguous.
+	CorrelationInput string
 }
 
 // WorkflowRunHandler handles the [WorkflowRun] command.
 type WorkflowRunHandler struct {
 	Client *github.Client
 	Logger *slog.Logger
+
+	// PollInterval is the time between two consecutive workflow run lookups.
+	//
+	// Defaults to 5 seconds.
+	PollInterval time.Duration
+
+	// FindTimeout is the maximum time to wait for the dispatched workflow run to show up.
+	//
+	// Defaults to 1 minute.
+	FindTimeout time.Duration
+
+	// WatchTimeout is the maximum time to wait for the workflow run to complete when [WorkflowRun.Watch] is set.
+	//
+	// Defaults to 10 minutes.
+	WatchTimeout time.Duration
 }
 
-// Handle executes the [Assign] command.
+const (
+	defaultWorkflowRunPollInterval = 5 * time.Second
+	defaultWorkflowRunFindTimeout  = time.Minute
+	defaultWorkflowRunWatchTimeout = 10 * time.Minute
+
+	// workflowRunClockSkew accounts for the difference between the local clock and GitHub's clock
+	// when looking for workflow runs created after the dispatch.
+	workflowRunClockSkew = 10 * time.Second
+)
+
+// Handle executes the [WorkflowRun] command.
 func (h WorkflowRunHandler) Handle(ctx context.Context, cmd WorkflowRun) error {
 	repo := cmd.Repo
 	issue := cmd.Issue
 
 	logger := h.Logger.With(slog.Int("number", issue.GetNumber()))
 
-	if !issue.IsPullRequest() {
-		return errors.New("cannot run workflow for issues")
+	ref := cmd.Ref
+
+	if ref == "" {
+		if !issue.IsPullRequest() {
+			return errors.New("cannot run workflow for issues without a ref")
+		}
+
+		logger.Info("fetching pull request details")
+
+		pr, _, err := h.Client.PullRequests.Get(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			issue.GetNumber(),
+		)
+		if err != nil {
+			return err
+		}
+
+		ref = pr.GetHead().GetRef()
 	}
 
-	logger.Info("fetching pull request details")
+	logger.Info("running workflow", slog.String("workflow", cmd.WorkflowFileName))
 
-	pr, _, err := h.Client.PullRequests.Get(
+	dispatch := workflowDispatch{
+		workflowFileName: cmd.WorkflowFileName,
+		ref:              ref,
+		since:            time.Now().Add(-workflowRunClockSkew),
+	}
+
+	inputs := cmd.Inputs
+
+	if cmd.CorrelationInput != "" {
+		dispatch.correlationID = rand.Text()
+
+		inputs = maps.Clone(inputs)
+		if inputs == nil {
+			inputs = make(map[string]any, 1)
+		}
+
+		inputs[cmd.CorrelationInput] = dispatch.correlationID
+	} else {
+		// Remember runs that already exist, so they are not mistaken for the dispatched run
+		runs, err := h.listWorkflowRuns(ctx, repo, dispatch)
+		if err != nil {
+			return fmt.Errorf("listing workflow runs: %w", err)
+		}
+
+		dispatch.existingRuns = make(map[int64]bool, len(runs))
+
+		for _, run := range runs {
+			dispatch.existingRuns[run.GetID()] = true
+		}
+	}
+
+	_, err := h.Client.Actions.CreateWorkflowDispatchEventByFileName(
 		ctx,
 		repo.GetOwner().GetLogin(),
 		repo.GetName(),
-		issue.GetNumber(),
+		cmd.WorkflowFileName,
+		github.CreateWorkflowDispatchEventRequest{
+			Ref:    ref,
+			Inputs: inputs,
+		},
 	)
 	if err != nil {
 		return err
 	}
 
-	logger.Info("running workflow", slog.String("workflow", cmd.WorkflowFileName))
+	logger.Info(
+		"looking for dispatched workflow run",
+		slog.String("workflow", cmd.WorkflowFileName),
+	)
+
+	run, err := h.findWorkflowRun(ctx, repo, dispatch)
+	if err != nil {
+		return fmt.Errorf("finding dispatched workflow run: %w", err)
+	}
+
+	logger = logger.With(slog.Int64("run_id", run.GetID()))
+
+	logger.Info("workflow run started", slog.String("url", run.GetHTMLURL()))
+
+	err = h.reply(ctx, cmd, fmt.Sprintf(
+		"Workflow run [%s #%d](%s) started.",
+		run.GetName(),
+		run.GetRunNumber(),
+		run.GetHTMLURL(),
+	))
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Watch {
+		return nil
+	}
+
+	logger.Info("watching workflow run")
+
+	run, err = h.watchWorkflowRun(ctx, repo, run)
+	if err != nil {
+		return fmt.Errorf("watching workflow run: %w", err)
+	}
+
+	logger.Info("workflow run completed", slog.String("conclusion", run.GetConclusion()))
 
-	_, err = h.Client.Actions.CreateWorkflowDispatchEventByFileName(
+	return h.reply(ctx, cmd, fmt.Sprintf(
+		"Workflow run [%s #%d](%s) completed with conclusion `%s`.",
+		run.GetName(),
+		run.GetRunNumber(),
+		run.GetHTMLURL(),
+		run.GetConclusion(),
+	))
+}
+
+// workflowDispatch identifies a dispatched workflow run.
+type workflowDispatch struct {
+	workflowFileName string
+	ref              string
+
+	// since is the time of the dispatch (adjusted by the clock skew)
+	since time.Time
+
+	// correlationID is included in the name of the dispatched run (if set)
+	correlationID string
+
+	// existingRuns are the IDs of runs that existed before the dispatch
+	existingRuns map[int64]bool
+}
+
+// matches checks if a run may be the dispatched run.
+func (d workflowDispatch) matches(run *github.WorkflowRun) bool {
+	if d.correlationID != "" {
+		return strings.Contains(run.GetDisplayTitle(), d.correlationID) ||
+			strings.Contains(run.GetName(), d.correlationID)
+	}
+
+	return !d.existingRuns[run.GetID()] && !run.GetCreatedAt().Before(d.since)
+}
+
+func (h WorkflowRunHandler) listWorkflowRuns(
+	ctx context.Context,
+	repo *github.Repository,
+	dispatch workflowDispatch,
+) ([]*github.WorkflowRun, error) {
+	runs, _, err := h.Client.Actions.ListWorkflowRunsByFileName(
 		ctx,
 		repo.GetOwner().GetLogin(),
 		repo.GetName(),
-		cmd.WorkflowFileName,
-		github.CreateWorkflowDispatchEventRequest{
-			Ref:    pr.GetHead().GetRef(),
-			Inputs: cmd.Inputs,
+		dispatch.workflowFileName,
+		&github.ListWorkflowRunsOptions{
+			Branch:  dispatch.ref,
+			Event:   "workflow_dispatch",
+			Created: ">=" + dispatch.since.UTC().Format(time.RFC3339),
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return runs.WorkflowRuns, nil
+}
+
+// findWorkflowRun polls the workflow runs of a workflow until the one created by the dispatch shows up.
+func (h WorkflowRunHandler) findWorkflowRun(
+	ctx context.Context,
+	repo *github.Repository,
+	dispatch workflowDispatch,
+) (*github.WorkflowRun, error) {
+	var run *github.WorkflowRun
+
+	timeout := withDefault(h.FindTimeout, defaultWorkflowRunFindTimeout)
+
+	err := poll(ctx, h.pollInterval(), timeout, func() (bool, error) {
+		runs, err := h.listWorkflowRuns(ctx, repo, dispatch)
+		if err != nil {
+			return false, err
+		}
+
+		var candidates []*github.WorkflowRun
+
+		for _, r := range runs {
+			if dispatch.matches(r) {
+				candidates = append(candidates, r)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			return false, nil
+
+		case 1:
+			run = candidates[0]
+
+			return true, nil
+
+		default:
+			if dispatch.correlationID != "" {
+				return false, fmt.Errorf(
+					"%d workflow runs match the correlation ID %s",
+					len(candidates),
+					dispatch.correlationID,
+				)
+			}
+
+			return false, fmt.Errorf(
+				"%d new workflow runs showed up after the dispatch (probably dispatched concurrently): "+
+					"configure a correlation input to tell them apart",
+				len(candidates),
+			)
+		}
+	})
+	if errors.Is(err, errPollTimeout) {
+		return nil, fmt.Errorf(
+			"workflow run did not show up within %s (check the workflow accepts workflow_dispatch events)",
+			timeout,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// watchWorkflowRun polls a workflow run until it completes.
+func (h WorkflowRunHandler) watchWorkflowRun(
+	ctx context.Context,
+	repo *github.Repository,
+	run *github.WorkflowRun,
+) (*github.WorkflowRun, error) {
+	runID := run.GetID()
+	htmlURL := run.GetHTMLURL()
+
+	timeout := withDefault(h.WatchTimeout, defaultWorkflowRunWatchTimeout)
+
+	err := poll(ctx, h.pollInterval(), timeout, func() (bool, error) {
+		var err error
+
+		run, _, err = h.Client.Actions.GetWorkflowRunByID(
+			ctx,
+			repo.GetOwner().GetLogin(),
+			repo.GetName(),
+			runID,
+		)
+		if err != nil {
+			return false, err
+		}
+
+		return run.GetStatus() == "completed", nil
+	})
+	if errors.Is(err, errPollTimeout) {
+		return nil, fmt.Errorf("workflow run %s did not complete within %s", htmlURL, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (h WorkflowRunHandler) reply(ctx context.Context, cmd WorkflowRun, body string) error {
+	if login := cmd.Comment.GetUser().GetLogin(); login != "" {
+		body = "@" + login + " " + body
+	}
+
+	_, _, err := h.Client.Issues.CreateComment(
+		ctx,
+		cmd.Repo.GetOwner().GetLogin(),
+		cmd.Repo.GetName(),
+		cmd.Issue.GetNumber(),
+		&github.IssueComment{
+			Body: github.Ptr(body),
+		},
+	)
+
+	return err
+}
+
+func (h WorkflowRunHandler) pollInterval() time.Duration {
+	return withDefault(h.PollInterval, defaultWorkflowRunPollInterval)
+}
+
+// errPollTimeout is returned by [poll] when the timeout expires.
+var errPollTimeout = errors.New("timed out")
+
+// poll calls fn every interval until it reports completion, returns an error or the timeout expires.
+func poll(
+	ctx context.Context,
+	interval time.Duration,
+	timeout time.Duration,
+	fn func() (bool, error),
+) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := fn()
+		if err != nil {
+			return err
+		}
+
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-timer.C:
+			return errPollTimeout
+
+		case <-ticker.C:
+		}
+	}
+}
+
+func withDefault[T comparable](value T, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+
+	return value
 }
 
 // NewWorkflowRunCommand creates a new Cobra command to run a workflow on a pull request.
@@ -87,6 +427,11 @@ func newWorkflowRunCommand(
 	event github.IssueCommentEvent,
 	handler commandHandler[WorkflowRun],
 ) *cobra.Command {
+	var (
+		watch            bool
+		correlationInput string
+	)
+
 	cmd := &cobra.Command{
 		Use:   "workflow-run",
 		Short: "Run a workflow on a pull request",
@@ -106,13 +451,30 @@ func newWorkflowRunCommand(
 			command := WorkflowRun{
 				Repo:             event.GetRepo(),
 				Issue:            event.GetIssue(),
+				Comment:          event.GetComment(),
 				WorkflowFileName: args[0],
 				Inputs:           inputs,
+				Watch:            watch,
+				CorrelationInput: correlationInput,
 			}
 
 			return handler.Handle(cmd.Context(), command)
 		},
 	}
 
+	cmd.Flags().BoolVar(
+		&watch,
+		"watch",
+		false,
+		"Wait for the workflow run to complete and report its conclusion",
+	)
+
+	cmd.Flags().StringVar(
+		&correlationInput,
+		"correlation-input",
+		"",
+		"Workflow input receiving a unique ID that the workflow includes in its run-name (to find the dispatched run reliably)",
+	)
+
 	return cmd
 }
@@ -0,0 +1,114 @@
+package builtin_test
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+func TestWorkflowRunHandler(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		issue                int
+		ref                  string
+		concurrentDispatches int
+		status               string
+		correlationInput     string
+		watch                bool
+		err                  string
+		comment              string
+	}{
+		{
+			name:    "single dispatch",
+			comment: "[ci.yaml #1]",
+		},
+		{
+			name:    "issue with ref",
+			issue:   1,
+			ref:     "main",
+			comment: "[ci.yaml #1]",
+		},
+		{
+			name:  "issue without ref",
+			issue: 1,
+			err:   "cannot run workflow for issues without a ref",
+		},
+		{
+			name:                 "concurrent dispatches",
+			concurrentDispatches: 2,
+			err:                  "configure a correlation input",
+		},
+		{
+			name:                 "concurrent dispatches with correlation input",
+			concurrentDispatches: 2,
+			correlationInput:     "dispatch-id",
+			comment:              "[ci.yaml #3]",
+		},
+		{
+			name:   "watch timeout",
+			status: "in_progress",
+			watch:  true,
+			err:    "did not complete within 10ms",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+			repo := server.AddRepository(newRepository())
+
+			repo.ConcurrentDispatches = testCase.concurrentDispatches
+			repo.WorkflowStatus = testCase.status
+
+			handler := builtin.WorkflowRunHandler{
+				Client:       server.Client(),
+				Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+				PollInterval: time.Millisecond,
+				FindTimeout:  10 * time.Millisecond,
+				WatchTimeout: 10 * time.Millisecond,
+			}
+
+			issue := repo.Issues[2]
+			if testCase.issue != 0 {
+				issue = repo.Issues[testCase.issue]
+			}
+
+			event := octoslashtest.NewIssueCommentEvent("", octoslashtest.WithIssue(issue))
+
+			err := handler.Handle(t.Context(), builtin.WorkflowRun{
+				Repo:             event.GetRepo(),
+				Issue:            event.GetIssue(),
+				Comment:          event.GetComment(),
+				WorkflowFileName: "ci.yaml",
+				Inputs:           map[string]any{"target": "staging"},
+				Ref:              testCase.ref,
+				Watch:            testCase.watch,
+				CorrelationInput: testCase.correlationInput,
+			})
+
+			if testCase.err != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.err) {
+					t.Fatalf("expected error containing %q, got %v", testCase.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			octoslashtest.AssertComment(t, issue, testCase.comment)
+
+			dispatch := repo.Dispatches[len(repo.Dispatches)-1]
+			if _, ok := dispatch.Inputs[testCase.correlationInput]; testCase.correlationInput != "" && !ok {
+				t.Errorf("expected correlation input %q, got %v", testCase.correlationInput, dispatch.Inputs)
+			}
+		})
+	}
+}
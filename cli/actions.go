@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/internal/markdown"
+)
+
+// writeActionsResults reports results to GitHub Actions (if running in GitHub Actions):
+// it writes a job summary to $GITHUB_STEP_SUMMARY and sets step outputs in $GITHUB_OUTPUT.
+func writeActionsResults(opts Options, results octoslash.Results) error {
+	if path := opts.Getenv("GITHUB_STEP_SUMMARY"); path != "" && len(results) > 0 {
+		err := appendFile(opts, path, func(w io.Writer) error {
+			return writeSummary(w, results)
+		})
+		if err != nil {
+			return fmt.Errorf("writing job summary: %w", err)
+		}
+	}
+
+	if path := opts.Getenv("GITHUB_OUTPUT"); path != "" {
+		err := appendFile(opts, path, func(w io.Writer) error {
+			return writeOutputs(w, results)
+		})
+		if err != nil {
+			return fmt.Errorf("writing step outputs: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func appendFile(opts Options, path string, write func(w io.Writer) error) error {
+	openFile := opts.OpenFile
+	if openFile == nil {
+		openFile = os.OpenFile
+	}
+
+	file, err := openFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeSummary writes a Markdown table of results.
+func writeSummary(w io.Writer, results octoslash.Results) error {
+	var b strings.Builder
+
+	b.WriteString("### Octoslash\n\n")
+	b.WriteString("| Command | Result | Details |\n")
+	b.WriteString("| --- | --- | --- |\n")
+
+	// Commands (and errors quoting them) come from comments: they must not inject Markdown into the summary
+	lineBreaks := strings.NewReplacer("\r", "", "\n", "<br>")
+
+	for _, result := range results {
+		var details string
+		if result.Err != nil {
+			details = lineBreaks.Replace(markdown.Escape(result.Err.Error()))
+		}
+
+		fmt.Fprintf(
+			&b,
+			"| %s | %s %s | %s |\n",
+			markdown.CodeSpan("/"+result.Command),
+			statusEmoji(result.Status),
+			result.Status,
+			details,
+		)
+	}
+
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func statusEmoji(status octoslash.ResultStatus) string {
+	switch status {
+	case octoslash.ResultSucceeded:
+		return "✅"
+
+	case octoslash.ResultDenied:
+		return "⛔"
+
+	case octoslash.ResultSkipped:
+		return "⏭️"
+
+	case octoslash.ResultRateLimited:
+		return "⏳"
+
+	default:
+		return "❌"
+	}
+}
+
+// actionsCommand is a command in the "commands" step output.
+type actionsCommand struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Status  string   `json:"status"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// writeOutputs writes step outputs:
+//
+//   - commands: every command in the comment with its result
+//   - executed: commands that were executed successfully
+//   - executed-names: resolved names of the commands that were executed successfully
+//     (without arguments, aliases are resolved to the name of the command)
+//   - denied: commands that were not authorized
+func writeOutputs(w io.Writer, results octoslash.Results) error {
+	commands := []actionsCommand{}
+	executed := []string{}
+	executedNames := []string{}
+	denied := []string{}
+
+	for _, result := range results {
+		command := actionsCommand{
+			Command: result.Command,
+			Args:    result.Args,
+			Status:  string(result.Status),
+		}
+
+		if result.Err != nil {
+			command.Error = result.Err.Error()
+		}
+
+		commands = append(commands, command)
+
+		switch result.Status {
+		case octoslash.ResultSucceeded:
+			executed = append(executed, result.Command)
+
+			name := result.Name
+			if name == "" && len(result.Args) > 0 {
+				name = result.Args[0]
+			}
+
+			if name != "" && !slices.Contains(executedNames, name) {
+				executedNames = append(executedNames, name)
+			}
+
+		case octoslash.ResultDenied:
+			denied = append(denied, result.Command)
+		}
+	}
+
+	outputs := []struct {
+		name  string
+		value any
+	}{
+		{"commands", commands},
+		{"executed", executed},
+		{"executed-names", executedNames},
+		{"denied", denied},
+	}
+
+	for _, output := range outputs {
+		value, err := json.Marshal(output.value)
+		if err != nil {
+			return err
+		}
+
+		// JSON encoding escapes newlines, so values always fit on a single line
+		_, err = fmt.Fprintf(w, "%s=%s\n", output.name, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+func TestWriteOutputs(t *testing.T) {
+	results := octoslash.Results{
+		{
+			Command: "label bug",
+			Args:    []string{"label", "bug"},
+			Name:    "add-label",
+			Status:  octoslash.ResultSucceeded,
+		},
+		{
+			Command: "add-label docs",
+			Args:    []string{"add-label", "docs"},
+			Name:    "add-label",
+			Status:  octoslash.ResultSucceeded,
+		},
+		{
+			Command: "close",
+			Args:    []string{"close"},
+			Status:  octoslash.ResultDenied,
+			Err:     fmt.Errorf("%w: nope", command.ErrUnauthorized),
+		},
+		{Command: "lable", Status: octoslash.ResultInvalid, Err: errors.New("unknown command")},
+	}
+
+	var buf bytes.Buffer
+
+	err := writeOutputs(&buf, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `commands=[{"command":"label bug","args":["label","bug"],"status":"succeeded"},{"command":"add-label docs","args":["add-label","docs"],"status":"succeeded"},{"command":"close","args":["close"],"status":"denied","error":"unauthorized: nope"},{"command":"lable","args":null,"status":"invalid","error":"unknown command"}]
+executed=["label bug","add-label docs"]
+executed-names=["add-label"]
+denied=["close"]
+`
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("unexpected outputs\nexpected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestWriteActionsResults_DefaultOpenFile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output")
+
+	// OpenFile is not set: files are opened with os.OpenFile
+	opts := Options{
+		Getenv: func(key string) string {
+			if key == "GITHUB_OUTPUT" {
+				return output
+			}
+
+			return ""
+		},
+	}
+
+	results := octoslash.Results{{Command: "close", Args: []string{"close"}, Status: octoslash.ResultSucceeded}}
+
+	err := writeActionsResults(opts, results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), `executed=["close"]`) {
+		t.Errorf("expected outputs to be written, got:\n%s", content)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	results := octoslash.Results{
+		{Command: "label a|b", Status: octoslash.ResultSucceeded},
+		{Command: "close", Status: octoslash.ResultFailed, Err: errors.New("line 1\nline 2")},
+		{
+			Command: "label `x` ![img](https://example.com/x.png)",
+			Status:  octoslash.ResultFailed,
+			Err:     errors.New("unknown label: [x](https://example.com) @alice"),
+		},
+	}
+
+	var buf bytes.Buffer
+
+	err := writeSummary(&buf, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "### Octoslash\n\n" +
+		"| Command | Result | Details |\n" +
+		"| --- | --- | --- |\n" +
+		"| `/label a\\|b` | ✅ succeeded |  |\n" +
+		"| `/close` | ❌ failed | line 1<br>line 2 |\n" +
+		"| ``/label `x` ![img](https://example.com/x.png)`` | ❌ failed | " +
+		"unknown label\\: \\[x\\]\\(https\\:\\/\\/example\\.com\\) \\@alice |\n\n"
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("unexpected summary\nexpected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Configuration sources.
+const (
+	// configSourceLocal loads configuration from a local directory.
+	configSourceLocal = "local"
+
+	// configSourceGitHub loads configuration from the repository using the GitHub API.
+	configSourceGitHub = "github"
+
+	// configSourceAuto loads configuration from a local directory if it exists, from GitHub otherwise.
+	configSourceAuto = "auto"
+)
+
+// defaultConfigSource returns the configuration source used when none is set explicitly.
+//
+// The working directory may contain untrusted content (eg. a checked out pull request branch),
+// so configuration is only loaded from a local directory if it is requested explicitly
+// (by setting the configuration source or the path of the directory).
+func defaultConfigSource(pathSet bool) string {
+	if pathSet {
+		return configSourceLocal
+	}
+
+	return configSourceGitHub
+}
+
+// openLocalConfig opens the local configuration directory according to the configuration source.
+//
+// It returns nil if configuration should be loaded from GitHub.
+func openLocalConfig(opts Options, source string, path string) (*os.Root, error) {
+	switch source {
+	case configSourceGitHub:
+		return nil, nil
+
+	case configSourceLocal, configSourceAuto:
+		root, err := opts.OpenRoot(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			if source == configSourceAuto {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf(
+				"configuration directory %q does not exist (use --config-path to point to a different directory or --config-source=github to load it from the repository)",
+				path,
+			)
+		} else if err != nil {
+			return nil, fmt.Errorf("opening configuration directory %q: %w", path, err)
+		}
+
+		return root, nil
+
+	default:
+		return nil, fmt.Errorf(
+			"unknown configuration source %q (valid sources: local, github, auto)",
+			source,
+		)
+	}
+}
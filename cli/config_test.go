@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenLocalConfig(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	opts := Options{OpenRoot: os.OpenRoot}
+
+	testCases := []struct {
+		name      string
+		source    string
+		path      string
+		wantLocal bool
+		wantErr   bool
+	}{
+		{name: "local", source: configSourceLocal, path: dir, wantLocal: true},
+		{name: "local missing", source: configSourceLocal, path: missing, wantErr: true},
+		{name: "auto", source: configSourceAuto, path: dir, wantLocal: true},
+		{name: "auto missing", source: configSourceAuto, path: missing},
+		{name: "github", source: configSourceGitHub, path: dir},
+		{name: "unknown", source: "somewhere", path: dir, wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			root, err := openLocalConfig(opts, testCase.source, testCase.path)
+			if testCase.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if root != nil {
+				defer root.Close()
+			}
+
+			if (root != nil) != testCase.wantLocal {
+				t.Errorf("expected local configuration: %v, got: %v", testCase.wantLocal, root != nil)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigSource(t *testing.T) {
+	// Local configuration must be requested explicitly
+	if source := defaultConfigSource(false); source != configSourceGitHub {
+		t.Errorf("expected %q, got %q", configSourceGitHub, source)
+	}
+
+	if source := defaultConfigSource(true); source != configSourceLocal {
+		t.Errorf("expected %q, got %q", configSourceLocal, source)
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/sagikazarmark/octoslash"
 	"github.com/sagikazarmark/octoslash/internal/app"
+	"github.com/sagikazarmark/octoslash/logging"
 )
 
 func init() {
@@ -26,6 +29,7 @@ type Options struct {
 	Stderr   io.Writer
 	Getenv   func(key string) string
 	Open     func(name string) (*os.File, error)
+	OpenFile func(name string, flag int, perm os.FileMode) (*os.File, error)
 	OpenRoot func(name string) (*os.Root, error)
 }
 
@@ -37,6 +41,7 @@ func DefaultOptions() Options {
 		Stderr:   os.Stderr,
 		Getenv:   os.Getenv,
 		Open:     os.Open,
+		OpenFile: os.OpenFile,
 		OpenRoot: os.OpenRoot,
 	}
 }
@@ -52,6 +57,10 @@ func (a Application) Main(os Options) error {
 		return errors.New("octoslash: no provider is set")
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		return a.simulate(os, os.Args[2:])
+	}
+
 	flags := pflag.NewFlagSet("octoslash", pflag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
 
@@ -62,15 +71,147 @@ func (a Application) Main(os Options) error {
 	flags.StringVar(&eventPath, "event-path", os.Getenv("GITHUB_EVENT_PATH"), "")
 
 	defaultConfigPath := filepath.Join(".github", "octoslash")
+	configPathSet := false
+
+	if v := os.Getenv("OCTOSLASH_CONFIG_PATH"); v != "" {
+		defaultConfigPath = v
+		configPathSet = true
+	}
 
 	var configPath string
-	flags.StringVar(&configPath, "config-path", defaultConfigPath, "")
+	flags.StringVar(
+		&configPath,
+		"config-path",
+		defaultConfigPath,
+		"Path of the configuration directory",
+	)
+
+	var configSource string
+	flags.StringVar(
+		&configSource,
+		"config-source",
+		os.Getenv("OCTOSLASH_CONFIG_SOURCE"),
+		"Where to load configuration from: local, github or auto (local if the directory exists, github otherwise); "+
+			"defaults to local if --config-path is set, github otherwise",
+	)
+
+	var cacheDir string
+	flags.StringVar(
+		&cacheDir,
+		"cache-dir",
+		os.Getenv("OCTOSLASH_CACHE_DIR"),
+		"Directory to cache configuration files loaded from GitHub in (caching is disabled if empty)",
+	)
+
+	var executionPolicy string
+	flags.StringVar(
+		&executionPolicy,
+		"execution-policy",
+		os.Getenv("OCTOSLASH_EXECUTION_POLICY"),
+		"How to handle failing commands: stop, continue or all-or-nothing",
+	)
+
+	var trigger string
+	flags.StringVar(
+		&trigger,
+		"trigger",
+		os.Getenv("OCTOSLASH_TRIGGER"),
+		"How commands are written: / (plain slash commands), /name (namespaced slash commands) or @name (mentions)",
+	)
+
+	var auditLog string
+	flags.StringVar(
+		&auditLog,
+		"audit-log",
+		os.Getenv("OCTOSLASH_AUDIT_LOG"),
+		"Append JSON lines audit records to a file (- for stdout)",
+	)
+
+	var auditSummary bool
+	flags.BoolVar(
+		&auditSummary,
+		"audit-summary",
+		os.Getenv("OCTOSLASH_AUDIT_SUMMARY") == "true",
+		"Write audit records to the GitHub Actions job summary",
+	)
+
+	var auditBranch string
+	flags.StringVar(
+		&auditBranch,
+		"audit-branch",
+		os.Getenv("OCTOSLASH_AUDIT_BRANCH"),
+		"Commit a Markdown audit log to a branch",
+	)
+
+	var auditBranchPath string
+	flags.StringVar(
+		&auditBranchPath,
+		"audit-branch-path",
+		os.Getenv("OCTOSLASH_AUDIT_BRANCH_PATH"),
+		"Path of the Markdown audit log on the audit branch",
+	)
+
+	defaultLogLevel := "info"
+	if os.Getenv("RUNNER_DEBUG") == "1" {
+		defaultLogLevel = "debug"
+	}
+
+	if v := os.Getenv("OCTOSLASH_LOG_LEVEL"); v != "" {
+		defaultLogLevel = v
+	}
+
+	var logLevel string
+	flags.StringVar(
+		&logLevel,
+		"log-level",
+		defaultLogLevel,
+		"Log level: debug, info, warn or error",
+	)
+
+	defaultLogFormat := string(logging.FormatText)
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		defaultLogFormat = string(logging.FormatGitHub)
+	}
+
+	if v := os.Getenv("OCTOSLASH_LOG_FORMAT"); v != "" {
+		defaultLogFormat = v
+	}
+
+	var logFormat string
+	flags.StringVar(&logFormat, "log-format", defaultLogFormat, "Log format: text, json or github")
 
 	err := flags.Parse(os.Args[1:])
 	if err != nil {
 		return err
 	}
 
+	// Explicitly requested sources must contain configuration
+	requireConfig := configSource == configSourceGitHub
+
+	if configSource == "" {
+		configSource = defaultConfigSource(configPathSet || flags.Changed("config-path"))
+	}
+
+	var level slog.Level
+
+	err = level.UnmarshalText([]byte(logLevel))
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+
+	format, err := logging.ParseFormat(logFormat)
+	if err != nil {
+		return err
+	}
+
+	var policy octoslash.ExecutionPolicy
+	if executionPolicy != "" {
+		policy, err = octoslash.ParseExecutionPolicy(executionPolicy)
+		if err != nil {
+			return err
+		}
+	}
+
 	if eventName != "issue_comment" {
 		return fmt.Errorf("unsupported event: %s", eventName)
 	}
@@ -80,17 +221,79 @@ func (a Application) Main(os Options) error {
 		return fmt.Errorf("loading event: %w", err)
 	}
 
+	apiURL, err := app.ResolveAPIURL(os.Getenv("GITHUB_API_URL"), os.Getenv("GITHUB_GRAPHQL_URL"))
+	if err != nil {
+		return err
+	}
+
 	var localFS fs.FS
 
-	handler, err := app.InitializeEventHandler(
+	root, err := openLocalConfig(os, configSource, configPath)
+	if err != nil {
+		return err
+	}
+
+	if root != nil {
+		defer root.Close()
+
+		localFS = root.FS()
+	}
+
+	config := app.Config{
+		Stdout:        os.Stdout,
+		Stderr:        os.Stderr,
+		OpenFile:      os.OpenFile,
+		Getenv:        os.Getenv,
+		ConfigPath:    filepath.ToSlash(configPath),
+		CacheDir:      cacheDir,
+		Trigger:       trigger,
+		APIURL:        apiURL,
+		RequireConfig: requireConfig,
+		Log: app.LogConfig{
+			Level:  level,
+			Format: format,
+		},
+		Audit: app.AuditConfig{
+			JSONLog:    auditLog,
+			Branch:     auditBranch,
+			BranchPath: auditBranchPath,
+		},
+	}
+
+	if auditSummary {
+		config.Audit.StepSummary = os.Getenv("GITHUB_STEP_SUMMARY")
+		if config.Audit.StepSummary == "" {
+			return errors.New("audit summary requested, but GITHUB_STEP_SUMMARY is not set")
+		}
+	}
+
+	handler, cleanup, err := app.InitializeEventHandler(
 		a.Provider,
 		app.Token(os.Getenv("GITHUB_TOKEN")),
 		event.GetRepo(),
 		localFS,
+		config,
 	)
 	if err != nil {
 		return fmt.Errorf("initializing event handler: %w", err)
 	}
 
-	return handler.Handle(context.Background(), event)
+	defer cleanup()
+
+	if executionPolicy != "" {
+		handler.ExecutionPolicy = policy
+	}
+
+	results, err := handler.Execute(context.Background(), event)
+	if err != nil {
+		err = fmt.Errorf(
+			"%d of %d commands did not succeed: %w",
+			len(results)-results.Count(octoslash.ResultSucceeded),
+			len(results),
+			err,
+		)
+	}
+
+	// Report results even if some commands failed
+	return errors.Join(err, writeActionsResults(os, results))
 }
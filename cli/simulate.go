@@ -0,0 +1,488 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/pflag"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/audit"
+	"github.com/sagikazarmark/octoslash/internal/app"
+	"github.com/sagikazarmark/octoslash/logging"
+)
+
+// simulate runs commands against a synthetic event without changing anything on GitHub.
+//
+// Read requests are sent to the GitHub API (eg. to load labels or entities for authorization),
+// but requests that would change anything are printed instead.
+func (a Application) simulate(os Options, args []string) error {
+	flags := pflag.NewFlagSet("octoslash simulate", pflag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+
+	var repo string
+	flags.StringVar(&repo, "repo", "", "Repository (owner/name)")
+
+	var issue int
+	flags.IntVar(&issue, "issue", 1, "Issue (or pull request) number")
+
+	var user string
+	flags.StringVar(&user, "user", "", "Login of the user commenting")
+
+	var userID int64
+	flags.Int64Var(
+		&userID,
+		"user-id",
+		0,
+		"ID of the user commenting (looked up on GitHub if not set)",
+	)
+
+	var author string
+	flags.StringVar(
+		&author,
+		"author",
+		"",
+		"Login of the issue author (defaults to the actual author of the issue)",
+	)
+
+	var configPath string
+	flags.StringVar(
+		&configPath,
+		"config",
+		filepath.Join(".github", "octoslash"),
+		"Configuration directory",
+	)
+
+	var trigger string
+	flags.StringVar(
+		&trigger,
+		"trigger",
+		os.Getenv("OCTOSLASH_TRIGGER"),
+		"How commands are written: / (plain slash commands), /name (namespaced slash commands) or @name (mentions)",
+	)
+
+	var logLevel string
+	flags.StringVar(&logLevel, "log-level", "warn", "Log level: debug, info, warn or error")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		return fmt.Errorf("invalid repository %q: expected owner/name", repo)
+	}
+
+	if user == "" {
+		return errors.New("the commenting user is required (--user)")
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("no commands to simulate")
+	}
+
+	var level slog.Level
+
+	err = level.UnmarshalText([]byte(logLevel))
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+
+	root, err := os.OpenRoot(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("configuration directory %q does not exist", configPath)
+	} else if err != nil {
+		return fmt.Errorf("opening configuration directory: %w", err)
+	}
+	defer root.Close()
+
+	ctx := context.Background()
+
+	apiURL, err := app.ResolveAPIURL(os.Getenv("GITHUB_API_URL"), os.Getenv("GITHUB_GRAPHQL_URL"))
+	if err != nil {
+		return err
+	}
+
+	config := app.Config{
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+		OpenFile: os.OpenFile,
+		Getenv:   os.Getenv,
+		Trigger:  trigger,
+		APIURL:   apiURL,
+		DryRun:   true,
+		Log: app.LogConfig{
+			Level:  level,
+			Format: logging.FormatText,
+		},
+	}
+
+	token := app.Token(os.Getenv("GITHUB_TOKEN"))
+
+	// Look up the details of the event with the same client the commands use (without intercepting requests)
+	lookupClient, err := app.NewClient(
+		a.Provider,
+		config,
+		token,
+		tracenoop.NewTracerProvider(),
+		metricnoop.NewMeterProvider(),
+	)
+	if err != nil {
+		return err
+	}
+
+	commenter := &github.User{Login: github.Ptr(user), ID: github.Ptr(userID)}
+	if userID == 0 {
+		commenter, err = lookupUser(ctx, lookupClient, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	eventRepo, _, err := lookupClient.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return fmt.Errorf("looking up repository %s: %w", repo, err)
+	}
+
+	eventIssue, _, err := lookupClient.Issues.Get(ctx, owner, name, issue)
+	if err != nil {
+		return fmt.Errorf("looking up issue %s#%d: %w", repo, issue, err)
+	}
+
+	if author != "" && author != eventIssue.GetUser().GetLogin() {
+		eventIssue.User, err = lookupUser(ctx, lookupClient, author)
+		if err != nil {
+			return err
+		}
+	}
+
+	event := newSimulatedEvent(eventRepo, eventIssue, commenter, strings.Join(flags.Args(), "\n"))
+
+	transport := &dryRunTransport{}
+
+	printer := &simulationPrinter{
+		w:         os.Stdout,
+		transport: transport,
+	}
+
+	// Read requests are sent through the original transport of the client (eg. for authentication)
+	config.WrapTransport = func(base http.RoundTripper) http.RoundTripper {
+		transport.Transport = base
+
+		return transport
+	}
+
+	config.Audit.Sinks = []audit.Sink{printer}
+
+	handler, cleanup, err := app.InitializeEventHandler(
+		a.Provider,
+		token,
+		event.GetRepo(),
+		root.FS(),
+		config,
+	)
+	if err != nil {
+		return fmt.Errorf("initializing event handler: %w", err)
+	}
+	defer cleanup()
+
+	results, err := handler.Execute(ctx, event)
+
+	printer.printResults(results)
+
+	if err != nil {
+		return fmt.Errorf(
+			"%d of %d commands did not succeed: %w",
+			len(results)-results.Count(octoslash.ResultSucceeded),
+			len(results),
+			err,
+		)
+	}
+
+	return nil
+}
+
+func lookupUser(ctx context.Context, client *github.Client, login string) (*github.User, error) {
+	user, _, err := client.Users.Get(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"looking up user %q (set the ID explicitly to skip the lookup): %w",
+			login,
+			err,
+		)
+	}
+
+	return user, nil
+}
+
+// simulatedCommentID is the ID of the synthetic comment.
+const simulatedCommentID = 1
+
+func newSimulatedEvent(
+	repo *github.Repository,
+	issue *github.Issue,
+	user *github.User,
+	body string,
+) github.IssueCommentEvent {
+	now := github.Timestamp{Time: time.Now()}
+
+	return github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Repo:   repo,
+		Issue:  issue,
+		Comment: &github.IssueComment{
+			ID:        github.Ptr(int64(simulatedCommentID)),
+			Body:      github.Ptr(body),
+			User:      user,
+			CreatedAt: &now,
+			UpdatedAt: &now,
+		},
+		Sender: user,
+	}
+}
+
+// dryRunTransport sends read requests to the underlying transport,
+// but records requests that would change anything and responds to them with a plausible successful response.
+//
+// Workflow dispatches are simulated as well: the dispatched run shows up immediately and is completed successfully,
+// so commands waiting for workflow runs do not poll the real runs of the workflow.
+type dryRunTransport struct {
+	Transport http.RoundTripper
+
+	mu      sync.Mutex
+	effects []string
+
+	// runs are the workflow runs of simulated dispatches
+	runs []*github.WorkflowRun
+}
+
+var (
+	workflowDispatchPath = regexp.MustCompile(`/repos/[^/]+/[^/]+/actions/workflows/([^/]+)/dispatches$`)
+	workflowRunsPath     = regexp.MustCompile(`/repos/[^/]+/[^/]+/actions/workflows/([^/]+)/runs$`)
+	workflowRunPath      = regexp.MustCompile(`/repos/[^/]+/[^/]+/actions/runs/(\d+)$`)
+
+	// issueLabelsPath matches endpoints changing the labels of an issue (they respond with the list of labels)
+	issueLabelsPath = regexp.MustCompile(`/repos/[^/]+/[^/]+/issues/\d+/labels(/.*)?$`)
+)
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		if resp := t.simulatedRuns(req); resp != nil {
+			return resp, nil
+		}
+
+		return t.Transport.RoundTrip(req)
+	}
+
+	effect := req.Method + " " + req.URL.Path
+
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		_ = req.Body.Close()
+
+		body = bytes.TrimSpace(body)
+		if len(body) > 0 {
+			effect += " " + string(body)
+		}
+	}
+
+	t.mu.Lock()
+	t.effects = append(t.effects, effect)
+	t.mu.Unlock()
+
+	if match := workflowDispatchPath.FindStringSubmatch(req.URL.Path); match != nil {
+		t.dispatch(match[1], body)
+
+		return newDryRunResponse(req, http.StatusNoContent, nil), nil
+	}
+
+	if issueLabelsPath.MatchString(req.URL.Path) {
+		return newDryRunResponse(req, http.StatusOK, []byte("[]")), nil
+	}
+
+	// Echo the request back (eg. the body of a created comment), as most endpoints respond with the changed resource
+	if !json.Valid(body) || !bytes.HasPrefix(body, []byte("{")) {
+		body = []byte("{}")
+	}
+
+	return newDryRunResponse(req, http.StatusOK, body), nil
+}
+
+// dispatch records the run of a simulated workflow dispatch.
+//
+// The run is titled after the workflow and the input values,
+// so it is found by correlation inputs (as if its run-name included every input).
+func (t *dryRunTransport) dispatch(workflow string, body []byte) {
+	var dispatch github.CreateWorkflowDispatchEventRequest
+
+	_ = json.Unmarshal(body, &dispatch)
+
+	title := workflow
+	for _, key := range slices.Sorted(maps.Keys(dispatch.Inputs)) {
+		title += fmt.Sprintf(" %v", dispatch.Inputs[key])
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.runs = append(t.runs, &github.WorkflowRun{
+		ID:           github.Ptr(int64(len(t.runs) + 1)),
+		Name:         github.Ptr(workflow),
+		DisplayTitle: github.Ptr(title),
+		RunNumber:    github.Ptr(len(t.runs) + 1),
+		Path:         github.Ptr(".github/workflows/" + workflow),
+		HeadBranch:   github.Ptr(dispatch.Ref),
+		Event:        github.Ptr("workflow_dispatch"),
+		Status:       github.Ptr("completed"),
+		Conclusion:   github.Ptr("success"),
+		CreatedAt:    &github.Timestamp{Time: time.Now()},
+	})
+}
+
+// simulatedRuns responds to workflow run lookups of workflows dispatched during the simulation
+// (or returns nil if the request is not such a lookup).
+func (t *dryRunTransport) simulatedRuns(req *http.Request) *http.Response {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if match := workflowRunsPath.FindStringSubmatch(req.URL.Path); match != nil {
+		runs := []*github.WorkflowRun{}
+
+		for _, run := range slices.Backward(t.runs) {
+			if run.GetName() == match[1] {
+				runs = append(runs, run)
+			}
+		}
+
+		if len(runs) == 0 {
+			return nil
+		}
+
+		body, _ := json.Marshal(github.WorkflowRuns{TotalCount: github.Ptr(len(runs)), WorkflowRuns: runs})
+
+		return newDryRunResponse(req, http.StatusOK, body)
+	}
+
+	if match := workflowRunPath.FindStringSubmatch(req.URL.Path); match != nil {
+		for _, run := range t.runs {
+			if strconv.FormatInt(run.GetID(), 10) == match[1] {
+				body, _ := json.Marshal(run)
+
+				return newDryRunResponse(req, http.StatusOK, body)
+			}
+		}
+	}
+
+	return nil
+}
+
+func newDryRunResponse(req *http.Request, status int, body []byte) *http.Response {
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+
+	if body != nil {
+		resp.Header.Set("Content-Type", "application/json")
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+	}
+
+	return resp
+}
+
+// flush returns the recorded effects and resets the list.
+func (t *dryRunTransport) flush() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	effects := t.effects
+	t.effects = nil
+
+	return effects
+}
+
+// simulationPrinter prints the decision and the intended effects of every command.
+type simulationPrinter struct {
+	w         io.Writer
+	transport *dryRunTransport
+}
+
+// Record implements [audit.Sink].
+func (p *simulationPrinter) Record(_ context.Context, record audit.Record) error {
+	fmt.Fprintf(p.w, "%s %s\n", record.Action, strings.Join(record.Args, " "))
+
+	decision := record.Decision
+	if decision == "" {
+		decision = "none"
+	}
+
+	if len(record.Policies) > 0 {
+		decision += fmt.Sprintf(" (policies: %s)", strings.Join(record.Policies, ", "))
+	}
+
+	fmt.Fprintf(p.w, "  decision: %s\n", decision)
+
+	effects := p.transport.flush()
+	if len(effects) == 0 {
+		fmt.Fprintln(p.w, "  effects:  none")
+	}
+
+	for i, effect := range effects {
+		label := "effects: "
+		if i > 0 {
+			label = "         "
+		}
+
+		fmt.Fprintf(p.w, "  %s %s\n", label, effect)
+	}
+
+	fmt.Fprintf(p.w, "  outcome:  %s\n", record.Outcome)
+
+	if record.Error != "" {
+		fmt.Fprintf(p.w, "  error:    %s\n", record.Error)
+	}
+
+	fmt.Fprintln(p.w)
+
+	return nil
+}
+
+func (p *simulationPrinter) printResults(results octoslash.Results) {
+	fmt.Fprintln(p.w, "Results:")
+
+	for _, result := range results {
+		fmt.Fprintf(p.w, "  /%s: %s\n", result.Command, result.Status)
+	}
+}
@@ -0,0 +1,428 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/audit"
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+func TestDryRunTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s request sent to the server", r.Method)
+		}
+
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	transport := &dryRunTransport{
+		Transport: http.DefaultTransport,
+	}
+
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/repos/owner/repo/labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.Post(
+		server.URL+"/repos/owner/repo/issues/12/labels",
+		"application/json",
+		strings.NewReader(`{"labels":["bug"]}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected a successful response, got %d", resp.StatusCode)
+	}
+
+	// Adding labels responds with the list of labels
+	if string(body) != "[]" {
+		t.Errorf("expected a plausible response body, got %q", body)
+	}
+
+	expected := []string{`POST /repos/owner/repo/issues/12/labels {"labels":["bug"]}`}
+
+	if effects := transport.flush(); !slices.Equal(effects, expected) {
+		t.Errorf("expected effects %v, got %v", expected, effects)
+	}
+
+	if effects := transport.flush(); len(effects) != 0 {
+		t.Errorf("expected effects to be reset, got %v", effects)
+	}
+}
+
+// newSimulateConfig creates a configuration directory allowing every command.
+func newSimulateConfig(t *testing.T) string {
+	t.Helper()
+
+	configPath := t.TempDir()
+
+	err := os.Mkdir(filepath.Join(configPath, "policies"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(
+		filepath.Join(configPath, "policies", "allow.cedar"),
+		[]byte("permit(principal, action, resource);\n"),
+		0o644,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return configPath
+}
+
+// simulate runs the simulate command against a repository of a test server.
+func simulate(t *testing.T, provider Provider, commands ...string) (string, error) {
+	t.Helper()
+
+	var stdout bytes.Buffer
+
+	app := Application{
+		Provider: provider,
+	}
+
+	err := app.Main(Options{
+		Args: append([]string{
+			"octoslash", "simulate",
+			"--repo=owner/repo",
+			"--issue=12",
+			"--user=octocat",
+			"--user-id=1",
+			"--config=" + newSimulateConfig(t),
+		}, commands...),
+		Stdout:   &stdout,
+		Stderr:   &bytes.Buffer{},
+		Getenv:   func(string) string { return "" },
+		OpenFile: os.OpenFile,
+		OpenRoot: os.OpenRoot,
+	})
+
+	return stdout.String(), err
+}
+
+// clientProvider supplies its own GitHub client.
+type clientProvider struct {
+	builtin.Provider
+
+	client *github.Client
+}
+
+func (p clientProvider) NewClient() *github.Client {
+	return p.client
+}
+
+func TestSimulate_ProviderClient(t *testing.T) {
+	server := octoslashtest.NewServer(t)
+	repo := server.AddRepository(&octoslashtest.Repository{
+		Owner: "owner",
+		Name:  "repo",
+		Issues: map[int]*octoslashtest.Issue{
+			12: {Number: 12, Author: "octocat"},
+		},
+	})
+
+	configPath := newSimulateConfig(t)
+
+	var stdout bytes.Buffer
+
+	app := Application{
+		Provider: clientProvider{client: server.Client()},
+	}
+
+	err := app.Main(Options{
+		Args: []string{
+			"octoslash", "simulate",
+			"--repo=owner/repo",
+			"--issue=12",
+			"--user=octocat",
+			"--user-id=1",
+			"--config=" + configPath,
+			"/close",
+		},
+		Stdout:   &stdout,
+		Stderr:   &bytes.Buffer{},
+		Getenv:   func(string) string { return "" },
+		OpenFile: os.OpenFile,
+		OpenRoot: os.OpenRoot,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stdout.String())
+	}
+
+	if requests := server.MutatingRequests(); len(requests) > 0 {
+		t.Errorf("expected no mutating requests, got %v", requests)
+	}
+
+	octoslashtest.AssertState(t, repo.Issues[12], "open", "")
+
+	if !strings.Contains(stdout.String(), "PATCH /repos/owner/repo/issues/12") {
+		t.Errorf("expected the intended effect to be printed, got:\n%s", stdout.String())
+	}
+}
+
+func TestSimulate_Trigger(t *testing.T) {
+	testCases := []struct {
+		name    string
+		env     map[string]string
+		builtin string
+	}{
+		{
+			name: "environment",
+			env:  map[string]string{"OCTOSLASH_TRIGGER": "@octoslash"},
+		},
+		{
+			name:    "repository configuration",
+			builtin: "trigger: '@octoslash'\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+			server.AddRepository(&octoslashtest.Repository{
+				Owner: "owner",
+				Name:  "repo",
+				Issues: map[int]*octoslashtest.Issue{
+					12: {Number: 12, Author: "octocat"},
+				},
+			})
+
+			configPath := newSimulateConfig(t)
+
+			if testCase.builtin != "" {
+				err := os.WriteFile(
+					filepath.Join(configPath, "builtin.yaml"),
+					[]byte(testCase.builtin),
+					0o644,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout bytes.Buffer
+
+			app := Application{
+				Provider: clientProvider{client: server.Client()},
+			}
+
+			err := app.Main(Options{
+				Args: []string{
+					"octoslash", "simulate",
+					"--repo=owner/repo",
+					"--issue=12",
+					"--user=octocat",
+					"--user-id=1",
+					"--config=" + configPath,
+					"@octoslash close",
+				},
+				Stdout:   &stdout,
+				Stderr:   &bytes.Buffer{},
+				Getenv:   func(key string) string { return testCase.env[key] },
+				OpenFile: os.OpenFile,
+				OpenRoot: os.OpenRoot,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v\n%s", err, stdout.String())
+			}
+
+			if !strings.Contains(stdout.String(), "PATCH /repos/owner/repo/issues/12") {
+				t.Errorf("expected the command to be simulated, got:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+func TestSimulate_WorkflowRun(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+	}{
+		{
+			name:    "new run",
+			command: "/workflow-run deploy.yml --watch",
+		},
+		{
+			name:    "correlation input",
+			command: "/workflow-run deploy.yml env=staging --watch --correlation-input run-id",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+			repo := server.AddRepository(&octoslashtest.Repository{
+				Owner: "owner",
+				Name:  "repo",
+				Issues: map[int]*octoslashtest.Issue{
+					12: {Number: 12, Author: "octocat", PullRequest: true, HeadRef: "feature"},
+				},
+			})
+
+			stdout, err := simulate(t, clientProvider{client: server.Client()}, testCase.command)
+			if err != nil {
+				t.Fatalf("unexpected error: %v\n%s", err, stdout)
+			}
+
+			if requests := server.MutatingRequests(); len(requests) > 0 {
+				t.Errorf("expected no mutating requests, got %v", requests)
+			}
+
+			if len(repo.Dispatches) > 0 {
+				t.Errorf("expected no workflow dispatches, got %v", repo.Dispatches)
+			}
+
+			for _, effect := range []string{
+				"POST /repos/owner/repo/actions/workflows/deploy.yml/dispatches",
+				"completed with conclusion `success`",
+			} {
+				if !strings.Contains(stdout, effect) {
+					t.Errorf("expected %q to be printed, got:\n%s", effect, stdout)
+				}
+			}
+		})
+	}
+}
+
+// eventProvider records the events dispatched to it.
+type eventProvider struct {
+	clientProvider
+
+	events *[]github.IssueCommentEvent
+}
+
+func (p eventProvider) NewCommandDispatcher() octoslash.CommandDispatcher {
+	return octoslash.CommandDispatcherFunc(func(_ context.Context, event github.IssueCommentEvent, _ []string) error {
+		*p.events = append(*p.events, event)
+
+		return nil
+	})
+}
+
+func TestSimulate_Event(t *testing.T) {
+	server := octoslashtest.NewServer(t)
+	server.AddRepository(&octoslashtest.Repository{
+		Owner:         "owner",
+		Name:          "repo",
+		DefaultBranch: "develop",
+		Issues: map[int]*octoslashtest.Issue{
+			12: {Number: 12, Author: "alice", State: "closed", Labels: []string{"bug"}},
+		},
+	})
+
+	var events []github.IssueCommentEvent
+
+	provider := eventProvider{
+		clientProvider: clientProvider{client: server.Client()},
+		events:         &events,
+	}
+
+	stdout, err := simulate(t, provider, "/reopen")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stdout)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected exactly one event, got %d", len(events))
+	}
+
+	event := events[0]
+
+	// The event reflects the actual issue, not assumptions about it
+	if state := event.GetIssue().GetState(); state != "closed" {
+		t.Errorf("expected the issue to be closed, got %q", state)
+	}
+
+	if author := event.GetIssue().GetUser().GetLogin(); author != "alice" {
+		t.Errorf("expected the issue author to be alice, got %q", author)
+	}
+
+	if labels := event.GetIssue().Labels; len(labels) != 1 || labels[0].GetName() != "bug" {
+		t.Errorf("expected the issue labels to be loaded, got %v", labels)
+	}
+
+	if branch := event.GetRepo().GetDefaultBranch(); branch != "develop" {
+		t.Errorf("expected the repository to be loaded, got default branch %q", branch)
+	}
+
+	if login := event.GetComment().GetUser().GetLogin(); login != "octocat" {
+		t.Errorf("expected the comment to be written by octocat, got %q", login)
+	}
+
+	if event.GetComment().GetID() == 0 {
+		t.Error("expected the comment to have an ID")
+	}
+}
+
+// auditProvider supplies its own audit sink.
+type auditProvider struct {
+	clientProvider
+
+	records *[]audit.Record
+}
+
+func (p auditProvider) NewAuditSink() audit.Sink {
+	return audit.SinkFunc(func(_ context.Context, record audit.Record) error {
+		*p.records = append(*p.records, record)
+
+		return nil
+	})
+}
+
+func TestSimulate_ProviderAuditSink(t *testing.T) {
+	server := octoslashtest.NewServer(t)
+	server.AddRepository(&octoslashtest.Repository{
+		Owner: "owner",
+		Name:  "repo",
+		Issues: map[int]*octoslashtest.Issue{
+			12: {Number: 12, Author: "octocat"},
+		},
+	})
+
+	var records []audit.Record
+
+	provider := auditProvider{
+		clientProvider: clientProvider{client: server.Client()},
+		records:        &records,
+	}
+
+	stdout, err := simulate(t, provider, "/close")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stdout)
+	}
+
+	if len(records) > 0 {
+		t.Errorf("expected no records in the provider sink during simulation, got %v", records)
+	}
+
+	// Simulated commands are still printed
+	if !strings.Contains(stdout, "PATCH /repos/owner/repo/issues/12") {
+		t.Errorf("expected the intended effect to be printed, got:\n%s", stdout)
+	}
+}
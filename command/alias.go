@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// Alias describes a command that invokes another command with additional arguments.
+type Alias struct {
+	// Args are passed to the target command before the arguments of the alias.
+	Args []string
+
+	// Prefix is prepended to every argument of the alias (eg. "kind/" turns "/kind bug" into "kind/bug").
+	Prefix string
+
+	// Short is the description of the alias.
+	Short string
+}
+
+// NewAliasCommand creates a Cobra command that invokes target with the arguments transformed according to alias.
+//
+// The alias is authorized as the target command (see [ActionAnnotation]).
+func NewAliasCommand(name string, target *cobra.Command, alias Alias) *cobra.Command {
+	short := alias.Short
+	if short == "" {
+		short = fmt.Sprintf("Alias for %s", target.Name())
+	}
+
+	// Positional arguments of the target command (after parsing its flags)
+	var targetArgs []string
+
+	return &cobra.Command{
+		Use:   name,
+		Short: short,
+		Annotations: map[string]string{
+			ActionAnnotation: ActionName(target),
+		},
+
+		// Flags belong to the target command
+		DisableFlagParsing: true,
+
+		// Parse and validate the arguments of the target command before authorization,
+		// so the target can set authorization context from its Args validator
+		Args: func(cmd *cobra.Command, args []string) error {
+			args = alias.expand(args)
+
+			target.SetContext(cmd.Context())
+
+			err := target.ParseFlags(args)
+			if err != nil {
+				return err
+			}
+
+			targetArgs = target.Flags().Args()
+
+			err = target.ValidateArgs(targetArgs)
+			if err != nil {
+				return err
+			}
+
+			// Propagate context (eg. authorization context) set by the target
+			cmd.SetContext(target.Context())
+
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			target.SetContext(cmd.Context())
+
+			if target.PreRunE != nil {
+				err := target.PreRunE(target, targetArgs)
+				if err != nil {
+					return err
+				}
+			} else if target.PreRun != nil {
+				target.PreRun(target, targetArgs)
+			}
+
+			cmd.SetContext(target.Context())
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			target.SetContext(cmd.Context())
+
+			if target.RunE != nil {
+				return target.RunE(target, targetArgs)
+			}
+
+			if target.Run != nil {
+				target.Run(target, targetArgs)
+			}
+
+			return nil
+		},
+	}
+}
+
+func (a Alias) expand(args []string) []string {
+	expanded := slices.Clone(a.Args)
+
+	for _, arg := range args {
+		expanded = append(expanded, a.Prefix+arg)
+	}
+
+	return expanded
+}
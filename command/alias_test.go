@@ -0,0 +1,108 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+type authorizerFunc func(ctx context.Context, event github.IssueCommentEvent, action string) error
+
+func (fn authorizerFunc) Authorize(
+	ctx context.Context,
+	event github.IssueCommentEvent,
+	action string,
+) error {
+	return fn(ctx, event, action)
+}
+
+func TestNewAliasCommand(t *testing.T) {
+	var (
+		action  string
+		attrs   map[string]any
+		preRun  []string
+		runArgs []string
+	)
+
+	authorizer := authorizerFunc(func(
+		ctx context.Context,
+		_ github.IssueCommentEvent,
+		a string,
+	) error {
+		action = a
+		attrs = command.AuthorizationContextFromContext(ctx)
+
+		if preRun != nil {
+			return errors.New("pre-run hooks should run after authorization")
+		}
+
+		return nil
+	})
+
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		var force bool
+
+		target := &cobra.Command{
+			Use: "add-label",
+			Args: func(cmd *cobra.Command, args []string) error {
+				cmd.SetContext(command.WithAuthorizationContext(cmd.Context(), map[string]any{
+					"labels": args,
+					"force":  force,
+				}))
+
+				return nil
+			},
+			PreRunE: func(_ *cobra.Command, args []string) error {
+				preRun = args
+
+				return nil
+			},
+			RunE: func(_ *cobra.Command, args []string) error {
+				runArgs = args
+
+				return nil
+			},
+		}
+
+		target.Flags().BoolVar(&force, "force", false, "")
+
+		rootCmd.AddCommand(target)
+		rootCmd.AddCommand(command.NewAliasCommand("kind", target, command.Alias{
+			Args:   []string{"--force"},
+			Prefix: "kind/",
+		}))
+
+		return rootCmd
+	})
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer:      authorizer,
+		CommandProvider: provider,
+	}
+
+	err := dispatcher.Dispatch(t.Context(), github.IssueCommentEvent{}, []string{"kind", "bug"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if action != "add-label" {
+		t.Errorf("expected the alias to be authorized as add-label, got %q", action)
+	}
+
+	if labels, _ := attrs["labels"].([]string); !slices.Equal(labels, []string{"kind/bug"}) ||
+		attrs["force"] != true {
+		t.Errorf("expected authorization context set by the target, got %v", attrs)
+	}
+
+	if !slices.Equal(preRun, []string{"kind/bug"}) || !slices.Equal(runArgs, []string{"kind/bug"}) {
+		t.Errorf("expected the target to run with kind/bug, got %v and %v", preRun, runArgs)
+	}
+}
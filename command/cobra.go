@@ -3,8 +3,9 @@ package command
 import (
 	"bytes"
 	"context"
-	"errors"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/google/go-github/v74/github"
 	"github.com/spf13/cobra"
@@ -13,6 +14,19 @@ import (
 type CobraDispatcher struct {
 	Authorizer      Authorizer
 	CommandProvider CommandProvider
+
+	// Middleware wraps the execution of every command.
+	//
+	// Authorization always runs after (inside) the configured middleware.
+	Middleware []Middleware
+
+	// ValidationMiddleware wraps the validation of every command (see [CobraDispatcher.Validate])
+	// and observes commands rejected before execution (see [CobraDispatcher.Dispatch] and [CobraDispatcher.Reject]).
+	//
+	// Unlike [CobraDispatcher.Middleware], it observes every rejected command,
+	// including unknown commands and commands with invalid arguments.
+	// The command of the invocation is nil for unknown commands.
+	ValidationMiddleware []Middleware
 }
 
 type Authorizer interface {
@@ -23,46 +37,181 @@ type CommandProvider interface {
 	NewCommand(event github.IssueCommentEvent) *cobra.Command
 }
 
+// Dispatch executes a command.
+//
+// Commands rejected before execution (eg. unknown commands or commands with invalid arguments)
+// are passed through [CobraDispatcher.ValidationMiddleware], since [CobraDispatcher.Middleware] never observes them.
 func (d CobraDispatcher) Dispatch(
 	ctx context.Context,
 	event github.IssueCommentEvent,
 	args []string,
 ) error {
-	cmd := d.newCommand(event, args)
+	var started bool
+
+	cmd := d.newCommand(event, args, false, func() { started = true })
+
+	// Resolve the invocation before flags are parsed
+	inv := d.newInvocation(cmd, event, args)
+
+	err := checkUnknownCommand(cmd, args)
+	if err == nil {
+		err = cmd.ExecuteContext(ctx)
+	}
+
+	if err != nil && !started {
+		return d.reject(ctx, inv, err)
+	}
+
+	return err
+}
+
+// Validate checks a command without executing it.
+//
+// The command is resolved, its arguments are validated, it is authorized and its PreRunE hooks are executed,
+// but the command itself is not executed.
+// Validation is wrapped by [CobraDispatcher.ValidationMiddleware] instead of [CobraDispatcher.Middleware].
+//
+// Commands must not cause side effects in PreRunE hooks.
+func (d CobraDispatcher) Validate(
+	ctx context.Context,
+	event github.IssueCommentEvent,
+	args []string,
+) error {
+	cmd := d.newCommand(event, args, true, nil)
 
-	return cmd.ExecuteContext(ctx)
+	handler := Chain(HandlerFunc(func(ctx context.Context, _ Invocation) error {
+		err := checkUnknownCommand(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return cmd.ExecuteContext(ctx)
+	}), d.ValidationMiddleware...)
+
+	return handler.Handle(ctx, d.newInvocation(cmd, event, args))
 }
 
-func (d CobraDispatcher) newCommand(event github.IssueCommentEvent, args []string) *cobra.Command {
+// Reject passes a command that could not be parsed through [CobraDispatcher.ValidationMiddleware].
+//
+// Since the command could not be parsed, the invocation is a best effort guess:
+// the text of the command is split on whitespace.
+func (d CobraDispatcher) Reject(
+	ctx context.Context,
+	event github.IssueCommentEvent,
+	command string,
+	err error,
+) {
+	inv := Invocation{
+		Event: event,
+	}
+
+	if fields := strings.Fields(command); len(fields) > 0 {
+		inv.Action = fields[0]
+		inv.Args = fields[1:]
+	}
+
+	_ = d.reject(ctx, inv, err)
+}
+
+func (d CobraDispatcher) reject(ctx context.Context, inv Invocation, err error) error {
+	handler := Chain(HandlerFunc(func(context.Context, Invocation) error {
+		return err
+	}), d.ValidationMiddleware...)
+
+	return handler.Handle(ctx, inv)
+}
+
+// newInvocation resolves the invocation of a command without executing it.
+//
+// Flags are not parsed: they are part of the positional arguments.
+func (d CobraDispatcher) newInvocation(
+	cmd *cobra.Command,
+	event github.IssueCommentEvent,
+	args []string,
+) Invocation {
+	inv := Invocation{
+		Event: event,
+		Args:  args,
+	}
+
+	if len(args) > 0 {
+		inv.Action = args[0]
+	}
+
+	if found, positional, err := cmd.Find(args); err == nil && found != cmd {
+		inv.Command = found
+		inv.Args = positional
+		inv.Action = ActionName(found)
+	}
+
+	return inv
+}
+
+func (d CobraDispatcher) newCommand(
+	event github.IssueCommentEvent,
+	args []string,
+	validate bool,
+	started func(),
+) *cobra.Command {
 	cmd := d.CommandProvider.NewCommand(event)
 
 	prevPersistentPreRunE := cmd.PersistentPreRunE
 
-	authorizer := d.Authorizer
+	// Authorization is the innermost middleware, so other middleware can observe denied requests
+	middleware := append(slices.Clone(d.Middleware), AuthorizationMiddleware(d.Authorizer))
+
+	if validate {
+		middleware = []Middleware{AuthorizationMiddleware(d.Authorizer)}
+	}
 
 	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		if authorizer == nil {
-			return errors.New("no authorizer configured, denying request")
+		if started != nil {
+			started()
 		}
 
-		action := cmd.Name()
+		RecordCommandName(cmd.Context(), CommandName(cmd))
+
+		// Run hooks after authorization (like the command itself),
+		// so unauthorized users cannot trigger them (eg. API lookups in PreRunE)
+		hooks := takePreRunHooks(cmd, prevPersistentPreRunE)
 
-		rootCmd := cmd.Root()
-		cmd.VisitParents(func(cmd *cobra.Command) {
-			if cmd == rootCmd {
-				return
+		// Wrap the command being executed with the middleware chain
+		runE := cmd.RunE
+		run := cmd.Run
+
+		handler := Chain(HandlerFunc(func(ctx context.Context, inv Invocation) error {
+			inv.Command.SetContext(ctx)
+
+			for _, hook := range hooks {
+				err := hook(inv.Command, inv.Args)
+				if err != nil {
+					return err
+				}
 			}
 
-			action = cmd.Name() + ":" + action
-		})
+			if validate {
+				return nil
+			}
 
-		err := authorizer.Authorize(cmd.Context(), event, action)
-		if err != nil {
-			return err
-		}
+			if runE != nil {
+				return runE(inv.Command, inv.Args)
+			}
 
-		if prevPersistentPreRunE != nil {
-			return prevPersistentPreRunE(cmd, args)
+			if run != nil {
+				run(inv.Command, inv.Args)
+			}
+
+			return nil
+		}), middleware...)
+
+		cmd.Run = nil
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return handler.Handle(cmd.Context(), Invocation{
+				Event:   event,
+				Command: cmd,
+				Args:    args,
+				Action:  ActionName(cmd),
+			})
 		}
 
 		return nil
@@ -82,3 +231,81 @@ func (d CobraDispatcher) newCommand(event github.IssueCommentEvent, args []strin
 
 	return cmd
 }
+
+// takePreRunHooks removes the pre-run hooks Cobra would run before the command (after the root PersistentPreRunE)
+// and returns them in the order Cobra would run them.
+func takePreRunHooks(
+	cmd *cobra.Command,
+	rootPersistentPreRunE func(cmd *cobra.Command, args []string) error,
+) []func(cmd *cobra.Command, args []string) error {
+	var hooks []func(cmd *cobra.Command, args []string) error
+
+	if rootPersistentPreRunE != nil {
+		hooks = append(hooks, rootPersistentPreRunE)
+	}
+
+	if cobra.EnableTraverseRunHooks {
+		var parents []*cobra.Command
+
+		for p := cmd; p != nil && p != cmd.Root(); p = p.Parent() {
+			parents = append([]*cobra.Command{p}, parents...)
+		}
+
+		for _, p := range parents {
+			hooks = appendHook(hooks, p.PersistentPreRunE, p.PersistentPreRun)
+			p.PersistentPreRunE, p.PersistentPreRun = nil, nil
+		}
+	}
+
+	hooks = appendHook(hooks, cmd.PreRunE, cmd.PreRun)
+	cmd.PreRunE, cmd.PreRun = nil, nil
+
+	return hooks
+}
+
+func appendHook(
+	hooks []func(cmd *cobra.Command, args []string) error,
+	runE func(cmd *cobra.Command, args []string) error,
+	run func(cmd *cobra.Command, args []string),
+) []func(cmd *cobra.Command, args []string) error {
+	switch {
+	case runE != nil:
+		return append(hooks, runE)
+
+	case run != nil:
+		return append(hooks, func(cmd *cobra.Command, args []string) error {
+			run(cmd, args)
+
+			return nil
+		})
+
+	default:
+		return hooks
+	}
+}
+
+// ActionAnnotation is a Cobra command annotation overriding the name of the authorization action of the command.
+const ActionAnnotation = "octoslash.action"
+
+// ActionName returns the name of the authorization action for a command.
+//
+// The action name is derived from the command path (without the root command),
+// unless overridden by the [ActionAnnotation] annotation.
+func ActionName(cmd *cobra.Command) string {
+	if action, ok := cmd.Annotations[ActionAnnotation]; ok {
+		return action
+	}
+
+	action := cmd.Name()
+
+	rootCmd := cmd.Root()
+	cmd.VisitParents(func(cmd *cobra.Command) {
+		if cmd == rootCmd {
+			return
+		}
+
+		action = cmd.Name() + ":" + action
+	})
+
+	return action
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+type authorizationContextKey struct{}
+
+// WithAuthorizationContext returns a copy of ctx carrying additional attributes for authorization requests.
+//
+// Attributes already present in ctx are kept unless overridden.
+//
+// Attributes must be set before the command is authorized: [CobraDispatcher] authorizes commands
+// after validating their arguments, but before running their PreRunE hooks.
+// Attributes derived from arguments can be set from the Args validator of the command.
+// Attributes that require looking up resources should be checked with [Authorize] instead.
+func WithAuthorizationContext(ctx context.Context, attrs map[string]any) context.Context {
+	merged := maps.Clone(AuthorizationContextFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]any, len(attrs))
+	}
+
+	maps.Copy(merged, attrs)
+
+	return context.WithValue(ctx, authorizationContextKey{}, merged)
+}
+
+// AuthorizationContextFromContext returns the authorization context attributes stored in ctx (if any).
+func AuthorizationContextFromContext(ctx context.Context) map[string]any {
+	attrs, _ := ctx.Value(authorizationContextKey{}).(map[string]any)
+
+	return attrs
+}
+
+type authorizeFuncKey struct{}
+
+// Authorize authorizes the command being executed again, with additional authorization context attributes.
+//
+// Commands call it from their PreRunE or RunE hooks when attributes require looking up resources
+// (eg. the title of a milestone): commands are authorized before their hooks run,
+// so unauthorized users cannot trigger lookups.
+// Since these attributes are missing from the first authorization request,
+// policies should check their presence (eg. context has milestone).
+//
+// Authorize denies the request if the command is not executed by [CobraDispatcher].
+func Authorize(ctx context.Context, attrs map[string]any) error {
+	authorize, ok := ctx.Value(authorizeFuncKey{}).(func(ctx context.Context) error)
+	if !ok {
+		RecordDecision(ctx, Decision{Allowed: false})
+
+		return fmt.Errorf("%w: no authorizer configured, denying request", ErrUnauthorized)
+	}
+
+	return authorize(WithAuthorizationContext(ctx, attrs))
+}
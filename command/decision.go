@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrUnauthorized is returned (wrapped) by authorizers when a command is denied.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Decision describes the outcome of an authorization request.
+type Decision struct {
+	// Allowed reports whether the request was allowed.
+	Allowed bool
+
+	// Policies lists the IDs of the policies that determined the decision.
+	Policies []string
+
+	// Errors lists errors encountered while evaluating policies.
+	Errors []string
+}
+
+type decisionRecorderKey struct{}
+
+type decisionRecorder struct {
+	mu       sync.Mutex
+	decision *Decision
+
+	// parent is the recorder of the parent context (if any)
+	parent *decisionRecorder
+}
+
+// WithDecisionRecorder returns a copy of ctx that collects the authorization decision made under it.
+//
+// The returned function returns the recorded decision (or nil if no decision was recorded).
+// Recorders can be nested: decisions are recorded by every recorder of the context.
+func WithDecisionRecorder(ctx context.Context) (context.Context, func() *Decision) {
+	parent, _ := ctx.Value(decisionRecorderKey{}).(*decisionRecorder)
+	recorder := &decisionRecorder{parent: parent}
+
+	return context.WithValue(ctx, decisionRecorderKey{}, recorder), func() *Decision {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+
+		return recorder.decision
+	}
+}
+
+// RecordDecision records an authorization decision if ctx carries a recorder (see [WithDecisionRecorder]).
+//
+// Authorizers call it to expose the details of their decisions (eg. for audit logging).
+func RecordDecision(ctx context.Context, decision Decision) {
+	recorder, _ := ctx.Value(decisionRecorderKey{}).(*decisionRecorder)
+
+	for ; recorder != nil; recorder = recorder.parent {
+		recorder.mu.Lock()
+		recorder.decision = &decision
+		recorder.mu.Unlock()
+	}
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+)
+
+// Invocation describes the execution of a command.
+type Invocation struct {
+	Event github.IssueCommentEvent
+
+	// Command is the Cobra command being executed.
+	Command *cobra.Command
+
+	// Args are the positional arguments of the command (after parsing flags).
+	Args []string
+
+	// Action is the name of the authorization action of the command (see [ActionName]).
+	Action string
+}
+
+// Handler executes a command invocation.
+type Handler interface {
+	Handle(ctx context.Context, inv Invocation) error
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as a [Handler].
+type HandlerFunc func(ctx context.Context, inv Invocation) error
+
+// Handle calls fn(ctx, inv).
+func (fn HandlerFunc) Handle(ctx context.Context, inv Invocation) error {
+	return fn(ctx, inv)
+}
+
+// Middleware wraps the execution of a command.
+//
+// Middleware runs after arguments are validated, but before authorization and PreRunE hooks
+// (see [CobraDispatcher]).
+type Middleware func(next Handler) Handler
+
+// Chain wraps a handler with a list of middleware.
+//
+// The first middleware is the outermost one.
+func Chain(handler Handler, middleware ...Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// AuthorizationMiddleware authorizes command invocations.
+//
+// Invocations are denied if no authorizer is configured.
+func AuthorizationMiddleware(authorizer Authorizer) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, inv Invocation) error {
+			if authorizer == nil {
+				RecordDecision(ctx, Decision{Allowed: false})
+
+				return fmt.Errorf("%w: no authorizer configured, denying request", ErrUnauthorized)
+			}
+
+			err := authorizer.Authorize(ctx, inv.Event, inv.Action)
+			if err != nil {
+				return err
+			}
+
+			ctx = context.WithValue(ctx, authorizeFuncKey{}, func(ctx context.Context) error {
+				return authorizer.Authorize(ctx, inv.Event, inv.Action)
+			})
+
+			return next.Handle(ctx, inv)
+		})
+	}
+}
@@ -0,0 +1,478 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+func TestCobraDispatcher_Middleware(t *testing.T) {
+	var calls []string
+
+	record := func(name string) command.Middleware {
+		return func(next command.Handler) command.Handler {
+			return command.HandlerFunc(func(ctx context.Context, inv command.Invocation) error {
+				calls = append(calls, name+":"+inv.Action)
+
+				return next.Handle(ctx, inv)
+			})
+		}
+	}
+
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		rootCmd.AddCommand(&cobra.Command{
+			Use: "hello",
+			PreRunE: func(*cobra.Command, []string) error {
+				calls = append(calls, "pre-run")
+
+				return nil
+			},
+			RunE: func(*cobra.Command, []string) error {
+				calls = append(calls, "run")
+
+				return nil
+			},
+		})
+
+		return rootCmd
+	})
+
+	testCases := []struct {
+		name       string
+		authorizer command.Authorizer
+		expected   []string
+		wantErr    bool
+	}{
+		{
+			name: "allowed",
+			authorizer: authorizerFunc(func(context.Context, github.IssueCommentEvent, string) error {
+				calls = append(calls, "authorize")
+
+				return nil
+			}),
+			expected: []string{"first:hello", "second:hello", "authorize", "pre-run", "run"},
+		},
+		{
+			name: "denied",
+			authorizer: authorizerFunc(func(context.Context, github.IssueCommentEvent, string) error {
+				calls = append(calls, "authorize")
+
+				return errors.New("denied")
+			}),
+			expected: []string{"first:hello", "second:hello", "authorize"},
+			wantErr:  true,
+		},
+		{
+			name:     "no authorizer",
+			expected: []string{"first:hello", "second:hello"},
+			wantErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			calls = nil
+
+			dispatcher := command.CobraDispatcher{
+				Authorizer:      testCase.authorizer,
+				CommandProvider: provider,
+				Middleware:      []command.Middleware{record("first"), record("second")},
+			}
+
+			err := dispatcher.Dispatch(t.Context(), github.IssueCommentEvent{}, []string{"hello"})
+
+			if testCase.wantErr && err == nil {
+				t.Error("expected an error")
+			} else if !testCase.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(calls, testCase.expected) {
+				t.Errorf("expected calls %v, got %v", testCase.expected, calls)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	var contexts []map[string]any
+
+	errDenied := errors.New("denied")
+
+	authorizer := authorizerFunc(func(
+		ctx context.Context,
+		_ github.IssueCommentEvent,
+		_ string,
+	) error {
+		attrs := command.AuthorizationContextFromContext(ctx)
+		contexts = append(contexts, attrs)
+
+		if attrs["milestone"] == "v2" {
+			return errDenied
+		}
+
+		return nil
+	})
+
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		rootCmd.AddCommand(&cobra.Command{
+			Use:  "milestone",
+			Args: cobra.ExactArgs(1),
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return command.Authorize(cmd.Context(), map[string]any{"milestone": args[0]})
+			},
+			RunE: func(*cobra.Command, []string) error {
+				return nil
+			},
+		})
+
+		return rootCmd
+	})
+
+	testCases := []struct {
+		milestone string
+		wantErr   bool
+	}{
+		{milestone: "v1"},
+		{milestone: "v2", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.milestone, func(t *testing.T) {
+			contexts = nil
+
+			dispatcher := command.CobraDispatcher{
+				Authorizer:      authorizer,
+				CommandProvider: provider,
+			}
+
+			err := dispatcher.Dispatch(
+				t.Context(),
+				github.IssueCommentEvent{},
+				[]string{"milestone", testCase.milestone},
+			)
+
+			if testCase.wantErr != errors.Is(err, errDenied) {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			// The command is authorized without the attribute first, then with it
+			if len(contexts) != 2 || contexts[0]["milestone"] != nil ||
+				contexts[1]["milestone"] != testCase.milestone {
+				t.Errorf("unexpected authorization contexts: %v", contexts)
+			}
+		})
+	}
+
+	t.Run("outside the dispatcher", func(t *testing.T) {
+		err := command.Authorize(t.Context(), nil)
+		if !errors.Is(err, command.ErrUnauthorized) {
+			t.Errorf("expected an unauthorized error, got %v", err)
+		}
+	})
+}
+
+func TestCobraDispatcher_Validate(t *testing.T) {
+	var calls []string
+
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		rootCmd.AddCommand(&cobra.Command{
+			Use:  "hello",
+			Args: cobra.ExactArgs(1),
+			RunE: func(*cobra.Command, []string) error {
+				calls = append(calls, "run")
+
+				return nil
+			},
+		})
+
+		return rootCmd
+	})
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer: authorizerFunc(func(_ context.Context, _ github.IssueCommentEvent, action string) error {
+			calls = append(calls, "authorize")
+
+			return nil
+		}),
+		CommandProvider: provider,
+		Middleware: []command.Middleware{func(next command.Handler) command.Handler {
+			calls = append(calls, "middleware")
+
+			return next
+		}},
+		ValidationMiddleware: []command.Middleware{func(next command.Handler) command.Handler {
+			return command.HandlerFunc(func(ctx context.Context, inv command.Invocation) error {
+				err := next.Handle(ctx, inv)
+
+				calls = append(calls, "validation "+inv.Action)
+				if err != nil {
+					calls = append(calls, "rejected")
+				}
+
+				return err
+			})
+		}},
+	}
+
+	err := dispatcher.Validate(t.Context(), github.IssueCommentEvent{}, []string{"hello", "world"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"authorize", "validation hello"}; !slices.Equal(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+
+	// Validation middleware observes commands rejected before authorization
+	for _, args := range [][]string{{"hello"}, {"unknown"}} {
+		calls = nil
+
+		err := dispatcher.Validate(t.Context(), github.IssueCommentEvent{}, args)
+		if err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+
+		expected := []string{"validation " + args[0], "rejected"}
+		if !slices.Equal(calls, expected) {
+			t.Errorf("%v: expected calls %v, got %v", args, expected, calls)
+		}
+	}
+}
+
+func TestCobraDispatcher_Dispatch_Rejected(t *testing.T) {
+	var calls []string
+
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		rootCmd.AddCommand(&cobra.Command{
+			Use:  "hello",
+			Args: cobra.ExactArgs(1),
+			RunE: func(*cobra.Command, []string) error {
+				calls = append(calls, "run")
+
+				return nil
+			},
+		})
+
+		return rootCmd
+	})
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer: authorizerFunc(func(context.Context, github.IssueCommentEvent, string) error {
+			return nil
+		}),
+		CommandProvider: provider,
+		ValidationMiddleware: []command.Middleware{func(next command.Handler) command.Handler {
+			return command.HandlerFunc(func(ctx context.Context, inv command.Invocation) error {
+				err := next.Handle(ctx, inv)
+
+				calls = append(calls, "rejected "+inv.Action)
+
+				return err
+			})
+		}},
+	}
+
+	// Executed commands are not observed by the validation middleware
+	err := dispatcher.Dispatch(t.Context(), github.IssueCommentEvent{}, []string{"hello", "world"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"run"}; !slices.Equal(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+
+	// Commands rejected before execution are
+	for _, args := range [][]string{{"hello"}, {"hello", "--unknown", "world"}, {"unknown"}} {
+		calls = nil
+
+		err := dispatcher.Dispatch(t.Context(), github.IssueCommentEvent{}, args)
+		if err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+
+		if expected := []string{"rejected " + args[0]}; !slices.Equal(calls, expected) {
+			t.Errorf("%v: expected calls %v, got %v", args, expected, calls)
+		}
+	}
+
+	calls = nil
+
+	dispatcher.Reject(t.Context(), github.IssueCommentEvent{}, "label 'a", errors.New("unterminated quote"))
+
+	if expected := []string{"rejected label"}; !slices.Equal(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestCobraDispatcher_CommandName(t *testing.T) {
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		workflowCmd := &cobra.Command{Use: "workflow"}
+		workflowCmd.AddCommand(&cobra.Command{Use: "run", RunE: func(*cobra.Command, []string) error { return nil }})
+
+		rootCmd.AddCommand(
+			&cobra.Command{
+				Use:     "add-label",
+				Aliases: []string{"label"},
+				RunE:    func(*cobra.Command, []string) error { return nil },
+			},
+			workflowCmd,
+		)
+
+		return rootCmd
+	})
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer: authorizerFunc(func(context.Context, github.IssueCommentEvent, string) error {
+			return nil
+		}),
+		CommandProvider: provider,
+	}
+
+	testCases := []struct {
+		args []string
+		name string
+	}{
+		{args: []string{"label", "bug"}, name: "add-label"},
+		{args: []string{"workflow", "run"}, name: "workflow run"},
+		{args: []string{"unknown"}},
+	}
+
+	for _, testCase := range testCases {
+		for _, fn := range []func(context.Context, github.IssueCommentEvent, []string) error{
+			dispatcher.Dispatch,
+			dispatcher.Validate,
+		} {
+			ctx, name := command.WithCommandNameRecorder(t.Context())
+
+			_ = fn(ctx, github.IssueCommentEvent{}, testCase.args)
+
+			if actual := name(); actual != testCase.name {
+				t.Errorf("%v: expected command name %q, got %q", testCase.args, testCase.name, actual)
+			}
+		}
+	}
+}
+
+func TestCobraDispatcher_UnknownCommand(t *testing.T) {
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		rootCmd.AddCommand(
+			&cobra.Command{Use: "label", RunE: func(*cobra.Command, []string) error { return nil }},
+			&cobra.Command{
+				Use:        "reopen",
+				SuggestFor: []string{"open"},
+				RunE:       func(*cobra.Command, []string) error { return nil },
+			},
+			&cobra.Command{
+				Use:     "close",
+				Aliases: []string{"shut"},
+				RunE:    func(*cobra.Command, []string) error { return nil },
+			},
+		)
+
+		return rootCmd
+	})
+
+	testCases := []struct {
+		name       string
+		suggestion string
+	}{
+		{name: "lable", suggestion: "label"},
+		{name: "lab", suggestion: "label"},
+		{name: "Clsoe", suggestion: "close"},
+		{name: "completion"},
+		{name: "open", suggestion: "reopen"},
+		{name: "assign"},
+	}
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer: authorizerFunc(func(context.Context, github.IssueCommentEvent, string) error {
+			return nil
+		}),
+		CommandProvider: provider,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, fn := range []func(context.Context, github.IssueCommentEvent, []string) error{
+				dispatcher.Dispatch,
+				dispatcher.Validate,
+			} {
+				err := fn(t.Context(), github.IssueCommentEvent{}, []string{testCase.name, "a"})
+
+				var unknownErr *command.UnknownCommandError
+				if !errors.As(err, &unknownErr) {
+					t.Fatalf("expected an unknown command error, got %v", err)
+				}
+
+				if unknownErr.Suggestion != testCase.suggestion {
+					t.Errorf("expected suggestion %q, got %q", testCase.suggestion, unknownErr.Suggestion)
+				}
+			}
+		})
+	}
+}
+
+func TestCobraDispatcher_Help(t *testing.T) {
+	provider := commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+		rootCmd := &cobra.Command{Use: "octoslash"}
+
+		rootCmd.AddCommand(
+			&cobra.Command{Use: "label", RunE: func(*cobra.Command, []string) error { return nil }},
+		)
+
+		return rootCmd
+	})
+
+	for _, args := range [][]string{{"help"}, {"help", "label"}} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			var actions []string
+
+			dispatcher := command.CobraDispatcher{
+				Authorizer: authorizerFunc(
+					func(_ context.Context, _ github.IssueCommentEvent, action string) error {
+						actions = append(actions, action)
+
+						return nil
+					},
+				),
+				CommandProvider: provider,
+			}
+
+			for _, fn := range []func(context.Context, github.IssueCommentEvent, []string) error{
+				dispatcher.Dispatch,
+				dispatcher.Validate,
+			} {
+				err := fn(t.Context(), github.IssueCommentEvent{}, args)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			// Help is authorized like any other command
+			if !slices.Equal(actions, []string{"help", "help"}) {
+				t.Errorf("expected the help action to be authorized, got %v", actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+type commandNameRecorderKey struct{}
+
+type commandNameRecorder struct {
+	mu   sync.Mutex
+	name string
+}
+
+// WithCommandNameRecorder returns a copy of ctx that collects the name of the command resolved under it.
+//
+// The returned function returns the recorded name (or an empty string if no command was resolved).
+func WithCommandNameRecorder(ctx context.Context) (context.Context, func() string) {
+	recorder := &commandNameRecorder{}
+
+	return context.WithValue(ctx, commandNameRecorderKey{}, recorder), func() string {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+
+		return recorder.name
+	}
+}
+
+// RecordCommandName records the name of the command resolved from the arguments
+// if ctx carries a recorder (see [WithCommandNameRecorder]).
+//
+// [CobraDispatcher] records the name of every command it resolves (see [CommandName]).
+func RecordCommandName(ctx context.Context, name string) {
+	recorder, _ := ctx.Value(commandNameRecorderKey{}).(*commandNameRecorder)
+	if recorder == nil {
+		return
+	}
+
+	recorder.mu.Lock()
+	recorder.name = name
+	recorder.mu.Unlock()
+}
+
+// CommandName returns the name of a command as it would be written in a comment without aliases
+// (ie. the command path without the root command, eg. "workflow run").
+func CommandName(cmd *cobra.Command) string {
+	return strings.TrimPrefix(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()), " ")
+}
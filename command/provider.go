@@ -0,0 +1,89 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+)
+
+var _ CommandProvider = CommandProviders(nil)
+
+// CommandProviders combines the command trees of multiple [CommandProvider] implementations.
+//
+// The root command of the first provider is used as the root of the combined tree.
+// Commands of subsequent providers are added to it, unless a command with the same name or alias already exists.
+// Use [CommandProviders.CheckConflicts] to detect such commands.
+type CommandProviders []CommandProvider
+
+func (p CommandProviders) NewCommand(event github.IssueCommentEvent) *cobra.Command {
+	rootCmd, _ := p.merge(event)
+
+	return rootCmd
+}
+
+// CheckConflicts returns an error listing the commands of subsequent providers
+// that are shadowed by a command with the same name or alias from a previous provider.
+func (p CommandProviders) CheckConflicts(event github.IssueCommentEvent) error {
+	_, conflicts := p.merge(event)
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf(
+			"commands conflict with existing commands (or their aliases): %s",
+			strings.Join(conflicts, ", "),
+		)
+	}
+
+	return nil
+}
+
+// merge combines the command trees and returns the names of the shadowed commands.
+func (p CommandProviders) merge(event github.IssueCommentEvent) (*cobra.Command, []string) {
+	var (
+		rootCmd   *cobra.Command
+		conflicts []string
+	)
+
+	for _, provider := range p {
+		cmd := provider.NewCommand(event)
+		if cmd == nil {
+			continue
+		}
+
+		if rootCmd == nil {
+			rootCmd = cmd
+
+			continue
+		}
+
+		for _, subCmd := range slices.Clone(cmd.Commands()) {
+			if hasCommand(rootCmd, subCmd) {
+				conflicts = append(conflicts, subCmd.Name())
+
+				continue
+			}
+
+			cmd.RemoveCommand(subCmd)
+			rootCmd.AddCommand(subCmd)
+		}
+	}
+
+	return rootCmd, conflicts
+}
+
+// hasCommand checks whether a command (or any of its aliases) is already registered under parent.
+func hasCommand(parent *cobra.Command, cmd *cobra.Command) bool {
+	names := append([]string{cmd.Name()}, cmd.Aliases...)
+
+	for _, c := range parent.Commands() {
+		for _, name := range names {
+			if c.Name() == name || c.HasAlias(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package command_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+type commandProviderFunc func(event github.IssueCommentEvent) *cobra.Command
+
+func (fn commandProviderFunc) NewCommand(event github.IssueCommentEvent) *cobra.Command {
+	return fn(event)
+}
+
+func TestCommandProviders(t *testing.T) {
+	newProvider := func(commands ...*cobra.Command) command.CommandProvider {
+		return commandProviderFunc(func(github.IssueCommentEvent) *cobra.Command {
+			rootCmd := &cobra.Command{Use: "octoslash"}
+
+			for _, cmd := range commands {
+				// Commands are created for every event
+				rootCmd.AddCommand(&cobra.Command{Use: cmd.Use, Aliases: cmd.Aliases})
+			}
+
+			return rootCmd
+		})
+	}
+
+	testCases := []struct {
+		name      string
+		providers command.CommandProviders
+		commands  []string
+		wantErr   bool
+	}{
+		{
+			name: "no conflicts",
+			providers: command.CommandProviders{
+				newProvider(&cobra.Command{Use: "close"}),
+				newProvider(&cobra.Command{Use: "triage"}),
+			},
+			commands: []string{"close", "triage"},
+		},
+		{
+			name: "name conflict",
+			providers: command.CommandProviders{
+				newProvider(&cobra.Command{Use: "close"}),
+				newProvider(&cobra.Command{Use: "close"}, &cobra.Command{Use: "triage"}),
+			},
+			commands: []string{"close", "triage"},
+			wantErr:  true,
+		},
+		{
+			name: "alias conflict",
+			providers: command.CommandProviders{
+				newProvider(&cobra.Command{Use: "add-label", Aliases: []string{"label"}}),
+				newProvider(&cobra.Command{Use: "label"}),
+			},
+			commands: []string{"add-label"},
+			wantErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.providers.CheckConflicts(github.IssueCommentEvent{})
+			if testCase.wantErr != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var commands []string
+			for _, cmd := range testCase.providers.NewCommand(github.IssueCommentEvent{}).Commands() {
+				commands = append(commands, cmd.Name())
+			}
+
+			if !slices.Equal(commands, testCase.commands) {
+				t.Errorf("expected commands %v, got %v", testCase.commands, commands)
+			}
+		})
+	}
+}
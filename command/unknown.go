@@ -0,0 +1,56 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// UnknownCommandError is returned when a command is not found.
+type UnknownCommandError struct {
+	// Name of the unknown command.
+	Name string
+
+	// Suggestion is the closest known command name (if any).
+	Suggestion string
+}
+
+func (e *UnknownCommandError) Error() string {
+	if e.Suggestion == "" {
+		return fmt.Sprintf("unknown command %q", e.Name)
+	}
+
+	return fmt.Sprintf("unknown command %q (did you mean %q?)", e.Name, e.Suggestion)
+}
+
+// checkUnknownCommand returns an [UnknownCommandError] if args do not resolve to a command in the tree.
+//
+// The default help command (/help) is a known command, the default completion command is not.
+func checkUnknownCommand(root *cobra.Command, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || !root.HasSubCommands() {
+		return nil
+	}
+
+	// Cobra only adds the help command when the command is executed
+	root.InitDefaultHelpCmd()
+
+	// Find only fails on unknown subcommands of a root command (that does not accept arguments)
+	_, _, err := root.Find(args)
+	if err == nil {
+		return nil
+	}
+
+	unknownErr := &UnknownCommandError{Name: args[0]}
+
+	// Cobra only sets the default distance when it reports unknown commands itself
+	if root.SuggestionsMinimumDistance <= 0 {
+		root.SuggestionsMinimumDistance = 2
+	}
+
+	if suggestions := root.SuggestionsFor(args[0]); len(suggestions) > 0 {
+		unknownErr.Suggestion = suggestions[0]
+	}
+
+	return unknownErr
+}
@@ -0,0 +1,242 @@
+package declarative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+var _ command.CommandProvider = CommandProvider{}
+
+// CommandProvider creates Cobra commands from a [Config].
+type CommandProvider struct {
+	Config Config
+
+	Client *github.Client
+	Logger *slog.Logger
+
+	// StrictLabels refuses to add labels that do not exist in the repository
+	// (see [builtin.AddLabelHandler.StrictLabels]).
+	StrictLabels bool
+
+	// LabelScopes configures label families (see [builtin.LabelScope]).
+	LabelScopes []builtin.LabelScope
+}
+
+func (p CommandProvider) NewCommand(event github.IssueCommentEvent) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "octoslash",
+		Short: "Custom slash commands",
+	}
+
+	handler := actionHandler{
+		AddLabels: builtin.AddLabelHandler{
+			Client:       p.Client,
+			Logger:       p.Logger,
+			StrictLabels: p.StrictLabels,
+			Scopes:       p.LabelScopes,
+		},
+		RemoveLabels: builtin.RemoveLabelHandler{
+			Client: p.Client,
+			Logger: p.Logger,
+		},
+		Comment: builtin.CommentHandler{
+			Client: p.Client,
+			Logger: p.Logger,
+		},
+		WorkflowRun: builtin.WorkflowRunHandler{
+			Client: p.Client,
+			Logger: p.Logger,
+		},
+	}
+
+	for _, cmd := range p.Config.Commands {
+		rootCmd.AddCommand(newCommand(event, cmd, handler))
+	}
+
+	return rootCmd
+}
+
+type commandHandler[T any] interface {
+	Handle(ctx context.Context, command T) error
+}
+
+type actionHandler struct {
+	AddLabels    commandHandler[builtin.AddLabel]
+	RemoveLabels commandHandler[builtin.RemoveLabel]
+	Comment      commandHandler[builtin.Comment]
+	WorkflowRun  commandHandler[builtin.WorkflowRun]
+}
+
+func newCommand(event github.IssueCommentEvent, cmd Command, handler actionHandler) *cobra.Command {
+	return &cobra.Command{
+		Use:     cmd.Name,
+		Aliases: cmd.Aliases,
+		Short:   cmd.Short,
+		Args:    argsValidator(cmd.Args),
+		RunE: func(c *cobra.Command, args []string) error {
+			data := newTemplateData(event, cmd, args)
+
+			// Render every action before executing any of them,
+			// so a template error does not leave the issue half-updated.
+			steps := make([]func(ctx context.Context) error, 0, len(cmd.Actions))
+
+			for i, action := range cmd.Actions {
+				step, err := newStep(event, action, data, handler)
+				if err != nil {
+					return fmt.Errorf("action %d: %w", i, err)
+				}
+
+				steps = append(steps, step)
+			}
+
+			for i, step := range steps {
+				err := step(c.Context())
+				if err != nil {
+					return fmt.Errorf("action %d: %w", i, err)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func newStep(
+	event github.IssueCommentEvent,
+	action Action,
+	data TemplateData,
+	handler actionHandler,
+) (func(ctx context.Context) error, error) {
+	repo := event.GetRepo()
+	issue := event.GetIssue()
+
+	switch {
+	case len(action.AddLabels) > 0:
+		labels, err := renderAll(action.AddLabels, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			if len(labels) == 0 {
+				return nil
+			}
+
+			return handler.AddLabels.Handle(ctx, builtin.AddLabel{
+				Repo:   repo,
+				Issue:  issue,
+				Labels: labels,
+			})
+		}, nil
+
+	case len(action.RemoveLabels) > 0:
+		labels, err := renderAll(action.RemoveLabels, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			if len(labels) == 0 {
+				return nil
+			}
+
+			return handler.RemoveLabels.Handle(ctx, builtin.RemoveLabel{
+				Repo:   repo,
+				Issue:  issue,
+				Labels: labels,
+			})
+		}, nil
+
+	case action.Comment != "":
+		body, err := render(action.Comment, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			return handler.Comment.Handle(ctx, builtin.Comment{
+				Repo:  repo,
+				Issue: issue,
+				Body:  body,
+			})
+		}, nil
+
+	case action.WorkflowDispatch != nil:
+		dispatch := action.WorkflowDispatch
+
+		workflow, err := render(dispatch.Workflow, data)
+		if err != nil {
+			return nil, err
+		}
+
+		ref, err := render(dispatch.Ref, data)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs := make(map[string]any, len(dispatch.Inputs))
+
+		for key, value := range dispatch.Inputs {
+			inputs[key], err = render(value, data)
+			if err != nil {
+				return nil, fmt.Errorf("input %q: %w", key, err)
+			}
+		}
+
+		return func(ctx context.Context) error {
+			return handler.WorkflowRun.Handle(ctx, builtin.WorkflowRun{
+				Repo:             repo,
+				Issue:            issue,
+				Comment:          event.GetComment(),
+				WorkflowFileName: workflow,
+				Inputs:           inputs,
+				Ref:              ref,
+				CorrelationInput: dispatch.CorrelationInput,
+			})
+		}, nil
+
+	default:
+		return nil, errors.New("unsupported action")
+	}
+}
+
+// argsValidator creates a Cobra argument validator from an argument specification.
+//
+// Arguments containing control characters are rejected (see [validateArg]).
+func argsValidator(args []Arg) cobra.PositionalArgs {
+	minArgs := 0
+	maxArgs := len(args)
+
+	for _, arg := range args {
+		if arg.Required {
+			minArgs++
+		}
+
+		if arg.Variadic {
+			maxArgs = -1
+		}
+	}
+
+	count := cobra.RangeArgs(minArgs, maxArgs)
+	if maxArgs < 0 {
+		count = cobra.MinimumNArgs(minArgs)
+	}
+
+	return cobra.MatchAll(count, func(_ *cobra.Command, args []string) error {
+		for _, arg := range args {
+			if err := validateArg(arg); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,205 @@
+package declarative_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/declarative"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+const config = `
+commands:
+  - name: triage
+    aliases: [t]
+    args:
+      - name: area
+        required: true
+      - name: note
+        variadic: true
+    actions:
+      - add-labels: ["triaged", "area/{{ .Args.area }}", "{{ if .Args.note }}has-note{{ end }}"]
+      - remove-labels: ["needs-*"]
+      - comment: "Triaged by @{{ .User }} in {{ .Repo.GetFullName }}#{{ .Issue.GetNumber }}: {{ .Args.note }}"
+  - name: deploy
+    args:
+      - name: environment
+        required: true
+    actions:
+      - workflow-dispatch:
+          workflow: deploy.yaml
+          ref: main
+          inputs:
+            environment: "{{ .Args.environment }}"
+            issue: "{{ .Issue.GetNumber }}"
+  - name: area
+    args:
+      - name: areas
+        variadic: true
+    actions:
+      - add-labels: ["{{ range .Args.areas }}area/{{ . }}\n{{ end }}"]
+      - comment: "Areas: {{ .Args.areas }}"
+  - name: prioritize
+    args:
+      - name: priority
+        required: true
+    actions:
+      - add-labels: ["priority/{{ .Args.priority }}"]
+  - name: broken
+    actions:
+      - comment: "{{ .Args.missing }}"
+      - add-labels: ["broken"]
+`
+
+func TestCommandProvider(t *testing.T) {
+	testCases := []struct {
+		name   string
+		body   string
+		status octoslash.ResultStatus
+		check  func(t *testing.T, server *octoslashtest.Server, repo *octoslashtest.Repository)
+	}{
+		{
+			name:   "labels and comment",
+			body:   "/t parser needs a second look",
+			status: octoslash.ResultSucceeded,
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "bug", "priority/low", "triaged", "area/parser", "has-note")
+				octoslashtest.AssertComment(
+					t,
+					repo.Issues[1],
+					"Triaged by @octocat in owner/repo#1: needs a second look",
+				)
+			},
+		},
+		{
+			name:   "empty labels are dropped",
+			body:   "/triage parser",
+			status: octoslash.ResultSucceeded,
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "bug", "priority/low", "triaged", "area/parser")
+			},
+		},
+		{
+			name:   "workflow dispatch",
+			body:   "/deploy staging",
+			status: octoslash.ResultSucceeded,
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertDispatched(t, repo, "deploy.yaml", "main")
+
+				inputs := repo.Dispatches[0].Inputs
+				if inputs["environment"] != "staging" || inputs["issue"] != "1" {
+					t.Errorf("unexpected workflow inputs: %v", inputs)
+				}
+			},
+		},
+		{
+			name:   "missing arguments",
+			body:   "/deploy",
+			status: octoslash.ResultFailed,
+			check: func(t *testing.T, server *octoslashtest.Server, _ *octoslashtest.Repository) {
+				octoslashtest.AssertNoRequests(t, server)
+			},
+		},
+		{
+			name:   "variadic arguments",
+			body:   "/area parser cli",
+			status: octoslash.ResultSucceeded,
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "bug", "needs-triage", "priority/low", "area/parser", "area/cli")
+				octoslashtest.AssertComment(t, repo.Issues[1], "Areas: parser cli")
+			},
+		},
+		{
+			name:   "exclusive label scope",
+			body:   "/prioritize high",
+			status: octoslash.ResultSucceeded,
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "bug", "needs-triage", "priority/high")
+			},
+		},
+		{
+			name:   "strict labels",
+			body:   "/prioritize unknown",
+			status: octoslash.ResultFailed,
+			check: func(t *testing.T, _ *octoslashtest.Server, repo *octoslashtest.Repository) {
+				octoslashtest.AssertLabels(t, repo.Issues[1], "bug", "needs-triage", "priority/low")
+			},
+		},
+		{
+			// A heredoc argument must not turn into multiple labels (escaping the prefix of the template)
+			name:   "multi-line argument",
+			body:   "/triage <<EOF\nbug\npriority/critical\nEOF",
+			status: octoslash.ResultFailed,
+			check: func(t *testing.T, server *octoslashtest.Server, _ *octoslashtest.Repository) {
+				octoslashtest.AssertNoRequests(t, server)
+			},
+		},
+		{
+			// Every action is rendered before executing any of them
+			name:   "template error",
+			body:   "/broken",
+			status: octoslash.ResultFailed,
+			check: func(t *testing.T, server *octoslashtest.Server, _ *octoslashtest.Repository) {
+				octoslashtest.AssertNoRequests(t, server)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := octoslashtest.NewServer(t)
+			repo := server.AddRepository(&octoslashtest.Repository{
+				Owner: "owner",
+				Name:  "repo",
+				Labels: []string{
+					"bug", "needs-triage", "triaged", "has-note",
+					"area/parser", "area/cli", "priority/low", "priority/high",
+				},
+				Issues: map[int]*octoslashtest.Issue{
+					1: {
+						Number: 1,
+						Author: "octocat",
+						Labels: []string{"bug", "needs-triage", "priority/low"},
+					},
+				},
+			})
+
+			cfg, err := declarative.LoadConfig(fstest.MapFS{
+				declarative.ConfigFileName: &fstest.MapFile{Data: []byte(config)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := octoslash.EventHandler{
+				Dispatcher: command.CobraDispatcher{
+					Authorizer: octoslashtest.AllowAll(),
+					CommandProvider: declarative.CommandProvider{
+						Config:       cfg,
+						Client:       server.Client(),
+						Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+						StrictLabels: true,
+						LabelScopes:  []builtin.LabelScope{{Prefix: "priority/", Exclusive: true}},
+					},
+				},
+			}
+
+			event := octoslashtest.NewIssueCommentEvent(
+				testCase.body,
+				octoslashtest.WithIssue(repo.Issues[1]),
+				octoslashtest.WithUser("octocat", 1),
+			)
+
+			results, _ := handler.Execute(t.Context(), event)
+
+			octoslashtest.AssertResults(t, results, testCase.status)
+
+			testCase.check(t, server, repo)
+		})
+	}
+}
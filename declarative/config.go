@@ -0,0 +1,252 @@
+package declarative
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the file declaring custom commands in the configuration directory.
+const ConfigFileName = "commands.yaml"
+
+// Config declares custom commands.
+type Config struct {
+	Commands []Command `yaml:"commands"`
+}
+
+// Command declares a custom command mapped to a list of primitive actions.
+type Command struct {
+	// Name of the command (also used as the name of the authorization action).
+	Name string `yaml:"name"`
+
+	// Aliases of the command.
+	Aliases []string `yaml:"aliases"`
+
+	// Short is the help text of the command.
+	Short string `yaml:"short"`
+
+	// Args declares the positional arguments of the command.
+	Args []Arg `yaml:"args"`
+
+	// Actions executed (in order) when the command is invoked.
+	Actions []Action `yaml:"actions"`
+}
+
+// Arg declares a positional argument of a [Command].
+type Arg struct {
+	// Name of the argument (used to refer to it in templates).
+	Name string `yaml:"name"`
+
+	// Required arguments must be provided when invoking the command.
+	// Required arguments must precede optional ones.
+	Required bool `yaml:"required"`
+
+	// Variadic arguments consume the rest of the positional arguments (see [VariadicArg]).
+	// Only the last argument may be variadic.
+	Variadic bool `yaml:"variadic"`
+}
+
+// Action declares a primitive action.
+//
+// Exactly one of the fields must be set.
+// Every string value is a Go template (see [TemplateData] for the available data).
+type Action struct {
+	// AddLabels adds labels to the issue or pull request.
+	AddLabels []string `yaml:"add-labels"`
+
+	// RemoveLabels removes labels (or glob patterns) from the issue or pull request.
+	RemoveLabels []string `yaml:"remove-labels"`
+
+	// Comment posts a comment on the issue or pull request.
+	Comment string `yaml:"comment"`
+
+	// WorkflowDispatch runs a workflow that accepts a workflow_dispatch trigger.
+	WorkflowDispatch *WorkflowDispatch `yaml:"workflow-dispatch"`
+}
+
+// WorkflowDispatch declares a workflow to run.
+type WorkflowDispatch struct {
+	// Workflow is the file name of the workflow.
+	Workflow string `yaml:"workflow"`
+
+	// Ref is the git reference to run the workflow on.
+	// Defaults to the head branch of the pull request.
+	Ref string `yaml:"ref"`
+
+	// Inputs of the workflow.
+	Inputs map[string]string `yaml:"inputs"`
+
+	// CorrelationInput is the name of a workflow input receiving a unique ID of the dispatch
+	// (see [builtin.WorkflowRun.CorrelationInput]).
+	CorrelationInput string `yaml:"correlation-input"`
+}
+
+// LoadConfig loads custom commands from [ConfigFileName] in fsys.
+//
+// A missing file results in an empty configuration.
+func LoadConfig(fsys fs.FS) (Config, error) {
+	var config Config
+
+	if fsys == nil {
+		return config, nil
+	}
+
+	b, err := fs.ReadFile(fsys, ConfigFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	err = yaml.Unmarshal(b, &config)
+	if err != nil {
+		return config, fmt.Errorf("decoding %s: %w", ConfigFileName, err)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return config, fmt.Errorf("validating %s: %w", ConfigFileName, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks the configuration for errors.
+func (c Config) Validate() error {
+	var errs []error
+
+	var names []string
+
+	for i, cmd := range c.Commands {
+		err := cmd.validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("command %d (%s): %w", i, cmd.Name, err))
+		}
+
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if slices.Contains(names, name) {
+				errs = append(errs, fmt.Errorf("command %d (%s): duplicate name %q", i, cmd.Name, name))
+			}
+
+			names = append(names, name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c Command) validate() error {
+	var errs []error
+
+	if c.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	if len(c.Actions) == 0 {
+		errs = append(errs, errors.New("at least one action is required"))
+	}
+
+	var argNames []string
+
+	optional := false
+
+	for i, arg := range c.Args {
+		if arg.Name == "" {
+			errs = append(errs, fmt.Errorf("arg %d: name is required", i))
+		} else if slices.Contains(argNames, arg.Name) {
+			errs = append(errs, fmt.Errorf("arg %d: duplicate name %q", i, arg.Name))
+		}
+
+		argNames = append(argNames, arg.Name)
+
+		if arg.Required && optional {
+			errs = append(errs, fmt.Errorf("arg %d (%s): required argument after optional one", i, arg.Name))
+		}
+
+		if !arg.Required {
+			optional = true
+		}
+
+		if arg.Variadic && i != len(c.Args)-1 {
+			errs = append(errs, fmt.Errorf("arg %d (%s): only the last argument may be variadic", i, arg.Name))
+		}
+	}
+
+	for i, action := range c.Actions {
+		err := action.validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("action %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a Action) validate() error {
+	set := 0
+
+	if len(a.AddLabels) > 0 {
+		set++
+	}
+
+	if len(a.RemoveLabels) > 0 {
+		set++
+	}
+
+	if a.Comment != "" {
+		set++
+	}
+
+	if a.WorkflowDispatch != nil {
+		set++
+
+		if a.WorkflowDispatch.Workflow == "" {
+			return errors.New("workflow-dispatch: workflow is required")
+		}
+	}
+
+	if set != 1 {
+		return errors.New("exactly one of add-labels, remove-labels, comment or workflow-dispatch is required")
+	}
+
+	return a.validateTemplates()
+}
+
+// validateTemplates parses every template of the action,
+// so syntax errors are reported when loading the configuration instead of when running the command.
+func (a Action) validateTemplates() error {
+	var errs []error
+
+	check := func(field string, text string) {
+		_, err := parseTemplate(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	for i, label := range a.AddLabels {
+		check(fmt.Sprintf("add-labels %d", i), label)
+	}
+
+	for i, label := range a.RemoveLabels {
+		check(fmt.Sprintf("remove-labels %d", i), label)
+	}
+
+	check("comment", a.Comment)
+
+	if dispatch := a.WorkflowDispatch; dispatch != nil {
+		check("workflow-dispatch: workflow", dispatch.Workflow)
+		check("workflow-dispatch: ref", dispatch.Ref)
+
+		for _, key := range slices.Sorted(maps.Keys(dispatch.Inputs)) {
+			check(fmt.Sprintf("workflow-dispatch: input %q", key), dispatch.Inputs[key])
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,173 @@
+package declarative_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/sagikazarmark/octoslash/declarative"
+)
+
+func TestLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		commands int
+		wantErr  bool
+	}{
+		{
+			name: "valid config",
+			config: `
+commands:
+  - name: triage
+    aliases: [t]
+    short: Triage an issue
+    args:
+      - name: area
+        required: true
+      - name: note
+        variadic: true
+    actions:
+      - add-labels: ["triaged", "area/{{ .Args.area }}"]
+      - remove-labels: ["needs-triage"]
+      - comment: "Triaged by @{{ .User }}"
+      - workflow-dispatch:
+          workflow: triage.yaml
+          ref: main
+          inputs:
+            issue: "{{ .Issue.Number }}"
+`,
+			commands: 1,
+		},
+		{
+			name: "missing name",
+			config: `
+commands:
+  - actions:
+      - comment: hello
+`,
+			wantErr: true,
+		},
+		{
+			name: "no actions",
+			config: `
+commands:
+  - name: hello
+`,
+			wantErr: true,
+		},
+		{
+			name: "multiple primitives in one action",
+			config: `
+commands:
+  - name: hello
+    actions:
+      - comment: hello
+        add-labels: [greeted]
+`,
+			wantErr: true,
+		},
+		{
+			name: "required argument after optional one",
+			config: `
+commands:
+  - name: hello
+    args:
+      - name: a
+      - name: b
+        required: true
+    actions:
+      - comment: hello
+`,
+			wantErr: true,
+		},
+		{
+			name: "variadic argument is not the last one",
+			config: `
+commands:
+  - name: hello
+    args:
+      - name: a
+        variadic: true
+      - name: b
+    actions:
+      - comment: hello
+`,
+			wantErr: true,
+		},
+		{
+			name: "template syntax error",
+			config: `
+commands:
+  - name: hello
+    actions:
+      - comment: hello
+      - add-labels: ["{{ .Args.area "]
+`,
+			wantErr: true,
+		},
+		{
+			name: "template syntax error in workflow input",
+			config: `
+commands:
+  - name: hello
+    actions:
+      - workflow-dispatch:
+          workflow: hello.yaml
+          inputs:
+            name: "{{ if .User }}"
+`,
+			wantErr: true,
+		},
+		{
+			name: "duplicate names",
+			config: `
+commands:
+  - name: hello
+    actions:
+      - comment: hello
+  - name: hi
+    aliases: [hello]
+    actions:
+      - comment: hi
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				declarative.ConfigFileName: &fstest.MapFile{Data: []byte(testCase.config)},
+			}
+
+			config, err := declarative.LoadConfig(fsys)
+
+			if testCase.wantErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(config.Commands) != testCase.commands {
+				t.Errorf("expected %d commands, got %d", testCase.commands, len(config.Commands))
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Missing(t *testing.T) {
+	config, err := declarative.LoadConfig(fstest.MapFS{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(config.Commands) != 0 {
+		t.Errorf("expected no commands, got %d", len(config.Commands))
+	}
+}
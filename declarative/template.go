@@ -0,0 +1,154 @@
+package declarative
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// TemplateData is the data available in action templates.
+type TemplateData struct {
+	// Args contains the positional arguments of the command by name:
+	// a string for regular arguments and a [VariadicArg] for variadic ones.
+	// Missing optional arguments are empty.
+	Args map[string]any
+
+	Repo    *github.Repository
+	Issue   *github.Issue
+	Comment *github.IssueComment
+
+	// User is the login of the comment author.
+	User string
+}
+
+// VariadicArg contains the values of a variadic argument.
+//
+// It renders as the values joined by a space (eg. in a comment).
+// Range over it to use the values one by one (eg. to add a label for each of them).
+type VariadicArg []string
+
+func (a VariadicArg) String() string {
+	return strings.Join(a, " ")
+}
+
+func newTemplateData(event github.IssueCommentEvent, cmd Command, args []string) TemplateData {
+	namedArgs := make(map[string]any, len(cmd.Args))
+
+	for i, arg := range cmd.Args {
+		switch {
+		case arg.Variadic && i >= len(args):
+			namedArgs[arg.Name] = VariadicArg{}
+
+		case arg.Variadic:
+			namedArgs[arg.Name] = VariadicArg(args[i:])
+
+		case i >= len(args):
+			namedArgs[arg.Name] = ""
+
+		default:
+			namedArgs[arg.Name] = args[i]
+		}
+	}
+
+	return TemplateData{
+		Args:    namedArgs,
+		Repo:    event.GetRepo(),
+		Issue:   event.GetIssue(),
+		Comment: event.GetComment(),
+		User:    event.GetComment().GetUser().GetLogin(),
+	}
+}
+
+// parseTemplate parses a template string.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+// render executes a template string.
+func render(text string, data TemplateData) (string, error) {
+	tpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	err = tpl.Execute(&b, data)
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// renderAll executes a list of template strings.
+//
+// Every line of a result ranging over a value (eg. a [VariadicArg]) is a separate item, empty lines are dropped.
+// Other results must be a single line: interpolated values are never split into multiple items.
+func renderAll(texts []string, data TemplateData) ([]string, error) {
+	results := make([]string, 0, len(texts))
+
+	for _, text := range texts {
+		tpl, err := parseTemplate(text)
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+
+		err = tpl.Execute(&b, data)
+		if err != nil {
+			return nil, err
+		}
+
+		result := b.String()
+
+		if !hasRange(tpl.Root) {
+			result = strings.TrimSpace(result)
+
+			if strings.ContainsAny(result, "\r\n") {
+				return nil, fmt.Errorf("template %q rendered multiple lines", text)
+			}
+
+			if result != "" {
+				results = append(results, result)
+			}
+
+			continue
+		}
+
+		for line := range strings.Lines(result) {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+
+			results = append(results, line)
+		}
+	}
+
+	return results, nil
+}
+
+// hasRange reports whether a template ranges over a value at the top level.
+func hasRange(root *parse.ListNode) bool {
+	return slices.ContainsFunc(root.Nodes, func(node parse.Node) bool {
+		return node.Type() == parse.NodeRange
+	})
+}
+
+// validateArg checks that an argument does not contain control characters (eg. newlines from a heredoc).
+//
+// Arguments are interpolated into labels (among others) and must not be able to turn into multiple values.
+func validateArg(arg string) error {
+	if strings.ContainsFunc(arg, unicode.IsControl) {
+		return fmt.Errorf("argument %q must not contain newlines or control characters", arg)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package octoslash_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/cli"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenProvider is the default provider of the octoslash binary with a client pointed at a fake GitHub API.
+type goldenProvider struct {
+	builtin.Provider
+
+	client *github.Client
+}
+
+func (p goldenProvider) NewClient() *github.Client {
+	return p.client
+}
+
+// TestGolden feeds every event in testdata through the CLI and compares
+// the resulting API requests, step outputs, logs and exit status with golden files in testdata/golden.
+//
+// Additional command line flags for an event (one per line) can be set in a file next to it with a .flags extension.
+// The configuration in testdata/config is available both locally and in the fake repository (in .github/octoslash).
+//
+// Run the tests with -update to regenerate golden files.
+func TestGolden(t *testing.T) {
+	events, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, eventPath := range events {
+		name := strings.TrimSuffix(filepath.Base(eventPath), ".json")
+
+		t.Run(name, func(t *testing.T) {
+			actual := runGolden(t, eventPath)
+
+			goldenPath := filepath.Join("testdata", "golden", name+".golden")
+
+			if *update {
+				err := os.WriteFile(goldenPath, []byte(actual), 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("reading golden file (run the tests with -update to create it): %v", err)
+			}
+
+			if actual != string(expected) {
+				t.Errorf(
+					"output does not match %s (run the tests with -update to update it)\nexpected:\n%s\nactual:\n%s",
+					goldenPath,
+					expected,
+					actual,
+				)
+			}
+		})
+	}
+}
+
+func runGolden(t *testing.T, eventPath string) string {
+	t.Helper()
+
+	eventFile, err := os.Open(eventPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eventFile.Close()
+
+	event, err := cli.LoadEvent(eventFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := readGoldenFlags(strings.TrimSuffix(eventPath, ".json") + ".flags")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newGoldenRepository(event)
+
+	repo.Files, err = readGoldenConfig(filepath.Join("testdata", "config"), ".github/octoslash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := octoslashtest.NewServer(t)
+	server.AddRepository(repo)
+
+	outputPath := filepath.Join(t.TempDir(), "output")
+
+	env := map[string]string{
+		"GITHUB_EVENT_NAME": "issue_comment",
+		"GITHUB_EVENT_PATH": eventPath,
+		"GITHUB_OUTPUT":     outputPath,
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	app := cli.Application{
+		Provider: goldenProvider{
+			client: server.Client(),
+		},
+	}
+
+	err = app.Main(cli.Options{
+		Args: append([]string{
+			"octoslash",
+			"--config-source=local",
+			"--config-path=" + filepath.Join("testdata", "config"),
+			"--cache-dir=",
+			"--log-format=text",
+			"--log-level=debug",
+		}, flags...),
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+		Getenv:   func(key string) string { return env[key] },
+		Open:     os.Open,
+		OpenFile: os.OpenFile,
+		OpenRoot: os.OpenRoot,
+	})
+
+	var b strings.Builder
+
+	b.WriteString("-- exit --\n")
+
+	if err != nil {
+		fmt.Fprintf(&b, "error: %s\n", err)
+	} else {
+		b.WriteString("ok\n")
+	}
+
+	b.WriteString("-- requests --\n")
+
+	for _, r := range server.Requests() {
+		b.WriteString(r.String())
+
+		if r.Body != "" {
+			b.WriteString(" " + r.Body)
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString("-- outputs --\n")
+
+	outputs, err := os.ReadFile(outputPath)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	b.Write(outputs)
+
+	b.WriteString("-- stdout --\n")
+	b.WriteString(stdout.String())
+
+	b.WriteString("-- logs --\n")
+	b.WriteString(stderr.String())
+
+	return normalizeGolden(b.String(), server.URL())
+}
+
+var (
+	goldenTimeRegexp     = regexp.MustCompile(`time=\S+ `)
+	goldenDurationRegexp = regexp.MustCompile(`duration=\S+`)
+)
+
+// normalizeGolden removes nondeterministic parts of the output.
+func normalizeGolden(s string, serverURL string) string {
+	s = strings.ReplaceAll(s, serverURL, "https://api.github.test/")
+	s = goldenTimeRegexp.ReplaceAllString(s, "")
+	s = goldenDurationRegexp.ReplaceAllString(s, "duration=0s")
+
+	return s
+}
+
+// readGoldenFlags reads additional command line flags of an event (if any).
+func readGoldenFlags(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(content)), nil
+}
+
+// readGoldenConfig reads the files of a local configuration directory, so they can be served by the fake repository.
+func readGoldenConfig(dir string, prefix string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		files[path.Join(prefix, filepath.ToSlash(rel))] = string(content)
+
+		return nil
+	})
+
+	return files, err
+}
+
+// newGoldenRepository creates a fake repository matching the event.
+func newGoldenRepository(event github.IssueCommentEvent) *octoslashtest.Repository {
+	issue := event.GetIssue()
+
+	repo := &octoslashtest.Repository{
+		Owner:         event.GetRepo().GetOwner().GetLogin(),
+		Name:          event.GetRepo().GetName(),
+		DefaultBranch: event.GetRepo().GetDefaultBranch(),
+		Issues: map[int]*octoslashtest.Issue{
+			issue.GetNumber(): {
+				Number:      issue.GetNumber(),
+				Title:       issue.GetTitle(),
+				State:       issue.GetState(),
+				Author:      issue.GetUser().GetLogin(),
+				Locked:      issue.GetLocked(),
+				PullRequest: issue.IsPullRequest(),
+			},
+		},
+	}
+
+	for _, label := range issue.Labels {
+		repo.Labels = append(repo.Labels, label.GetName())
+		repo.Issues[issue.GetNumber()].Labels = append(
+			repo.Issues[issue.GetNumber()].Labels,
+			label.GetName(),
+		)
+	}
+
+	return repo
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/audit"
+)
+
+// NewAuditSink returns the sink audit records are written to.
+//
+// Sinks supplied by the provider, additional sinks in the configuration
+// and sinks enabled by the audit options (eg. JSON log, job summary, branch) are combined.
+// Sinks supplied by the provider are disabled in dry run mode.
+// It returns nil if audit logging is not enabled.
+//
+// Files opened by the sink are closed by the returned cleanup function.
+func NewAuditSink(
+	provider Provider,
+	config Config,
+	client *github.Client,
+	repo *github.Repository,
+) (audit.Sink, func(), error) {
+	sinks := slices.Clone(audit.Sinks(config.Audit.Sinks))
+
+	// Sinks supplied by the provider may have side effects outside GitHub
+	if !config.DryRun {
+		switch p := provider.(type) {
+		case interface{ NewAuditSink() audit.Sink }:
+			sinks = append(sinks, p.NewAuditSink())
+
+		case interface {
+			NewAuditSink(client *github.Client) audit.Sink
+		}:
+			sinks = append(sinks, p.NewAuditSink(client))
+		}
+	}
+
+	openFile := config.OpenFile
+	if openFile == nil {
+		openFile = os.OpenFile
+	}
+
+	var files []*os.File
+
+	cleanup := func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}
+
+	switch config.Audit.JSONLog {
+	case "":
+
+	case "-":
+		sinks = append(sinks, audit.NewJSONSink(config.Stdout))
+
+	default:
+		file, err := openFile(
+			config.Audit.JSONLog,
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+			0o644,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening audit log: %w", err)
+		}
+
+		files = append(files, file)
+		sinks = append(sinks, audit.NewJSONSink(file))
+	}
+
+	if config.Audit.StepSummary != "" {
+		file, err := openFile(
+			config.Audit.StepSummary,
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+			0o644,
+		)
+		if err != nil {
+			cleanup()
+
+			return nil, nil, fmt.Errorf("opening job summary: %w", err)
+		}
+
+		files = append(files, file)
+		sinks = append(sinks, audit.NewSummarySink(file))
+	}
+
+	if config.Audit.Branch != "" {
+		sinks = append(sinks, &audit.BranchSink{
+			Client: client,
+			Owner:  repo.GetOwner().GetLogin(),
+			Repo:   repo.GetName(),
+			Branch: config.Audit.Branch,
+			Path:   config.Audit.BranchPath,
+		})
+	}
+
+	if len(sinks) == 0 {
+		return nil, cleanup, nil
+	}
+
+	return sinks, cleanup, nil
+}
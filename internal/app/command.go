@@ -2,32 +2,72 @@ package app
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
+	"sync"
 
 	"github.com/google/go-github/v74/github"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/audit"
+	"github.com/sagikazarmark/octoslash/builtin"
 	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/declarative"
+	"github.com/sagikazarmark/octoslash/parser"
+	"github.com/sagikazarmark/octoslash/telemetry"
 )
 
 func NewCommandDispatcher(
 	provider Provider,
 	def LazyResult[octoslash.CommandDispatcher],
+	logger *slog.Logger,
 ) (octoslash.CommandDispatcher, error) {
+	var dispatcher octoslash.CommandDispatcher
+
 	switch p := provider.(type) {
 	case interface {
 		NewCommandDispatcher() octoslash.CommandDispatcher
 	}:
-		return p.NewCommandDispatcher(), nil
+		dispatcher = p.NewCommandDispatcher()
+
+	default:
+		var err error
+
+		dispatcher, err = def.Resolve()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return octoslash.Chain(dispatcher, newDispatcherMiddleware(provider, logger)...), nil
+}
+
+func newDispatcherMiddleware(provider Provider, logger *slog.Logger) []octoslash.Middleware {
+	switch p := provider.(type) {
+	case interface {
+		DispatcherMiddleware() []octoslash.Middleware
+	}:
+		return p.DispatcherMiddleware()
 
 	default:
-		return def.Resolve()
+		return []octoslash.Middleware{
+			octoslash.RecoverMiddleware(),
+			octoslash.LoggerMiddleware(logger),
+		}
 	}
 }
 
 func DefaultCommandDispatcher(
+	provider Provider,
 	authorizer LazyResult[command.Authorizer],
 	commandProvider LazyResult[command.CommandProvider],
+	auditSink audit.Sink,
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+	logger *slog.Logger,
 ) LazyResult[octoslash.CommandDispatcher] {
 	return func() (octoslash.CommandDispatcher, error) {
 		authorizer, err := authorizer.Resolve()
@@ -40,9 +80,44 @@ func DefaultCommandDispatcher(
 			return nil, err
 		}
 
+		var middleware []command.Middleware
+
+		if p, ok := provider.(interface{ CommandMiddleware() []command.Middleware }); ok {
+			middleware = p.CommandMiddleware()
+		}
+
+		telemetryMiddleware, err := telemetry.Middleware(tracerProvider, meterProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		// Telemetry and audit logging are the outermost middleware, so they observe every command
+		builtinMiddleware := []command.Middleware{telemetryMiddleware}
+
+		var validationMiddleware []command.Middleware
+
+		if auditSink != nil {
+			builtinMiddleware = append(builtinMiddleware, audit.Middleware(auditSink, logger))
+			validationMiddleware = append(
+				validationMiddleware,
+				audit.ValidationMiddleware(auditSink, logger),
+			)
+		}
+
+		middleware = append(builtinMiddleware, middleware...)
+
+		if authorizer != nil {
+			authorizer = telemetry.Authorizer{
+				Authorizer:     authorizer,
+				TracerProvider: tracerProvider,
+			}
+		}
+
 		return command.CobraDispatcher{
-			Authorizer:      authorizer,
-			CommandProvider: commandProvider,
+			Authorizer:           authorizer,
+			CommandProvider:      commandProvider,
+			Middleware:           middleware,
+			ValidationMiddleware: validationMiddleware,
 		}, nil
 	}
 }
@@ -51,26 +126,177 @@ func NewCommandProvider(
 	provider Provider,
 	client *github.Client,
 	logger *slog.Logger,
+	fsys LazyResult[fs.FS],
+	declarativeCommandProvider LazyResult[*declarative.CommandProvider],
 ) LazyResult[command.CommandProvider] {
 	return func() (command.CommandProvider, error) {
-		switch p := provider.(type) {
-		case interface {
-			NewCommandProvider() command.CommandProvider
-		}:
-			return p.NewCommandProvider(), nil
+		commandProvider, err := newCommandProvider(provider, client, logger, fsys)
+		if err != nil {
+			return nil, err
+		}
 
-		case interface {
-			NewCommandProvider(client *github.Client) command.CommandProvider
-		}:
-			return p.NewCommandProvider(client), nil
+		declarativeCommandProvider, err := declarativeCommandProvider.Resolve()
+		if err != nil {
+			return nil, err
+		}
 
-		case interface {
-			NewCommandProvider(client *github.Client, logger *slog.Logger) command.CommandProvider
-		}:
-			return p.NewCommandProvider(client, logger), nil
+		if declarativeCommandProvider == nil {
+			return commandProvider, nil
+		}
 
-		default:
-			return nil, errors.New("no command provider")
+		providers := command.CommandProviders{commandProvider, declarativeCommandProvider}
+
+		// Fail early instead of silently ignoring custom commands shadowed by builtin ones
+		err = providers.CheckConflicts(github.IssueCommentEvent{})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid custom command configuration: %w (rename them or disable the builtin commands)",
+				err,
+			)
 		}
+
+		return providers, nil
 	}
 }
+
+func newCommandProvider(
+	provider Provider,
+	client *github.Client,
+	logger *slog.Logger,
+	fsys LazyResult[fs.FS],
+) (command.CommandProvider, error) {
+	switch p := provider.(type) {
+	case interface {
+		NewConfiguredCommandProvider(
+			client *github.Client,
+			logger *slog.Logger,
+			fsys fs.FS,
+		) (command.CommandProvider, error)
+	}:
+		fsys, err := fsys.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		return p.NewConfiguredCommandProvider(client, logger, fsys)
+
+	case interface {
+		NewCommandProvider() command.CommandProvider
+	}:
+		return p.NewCommandProvider(), nil
+
+	case interface {
+		NewCommandProvider(client *github.Client) command.CommandProvider
+	}:
+		return p.NewCommandProvider(client), nil
+
+	case interface {
+		NewCommandProvider(client *github.Client, logger *slog.Logger) command.CommandProvider
+	}:
+		return p.NewCommandProvider(client, logger), nil
+
+	default:
+		return nil, errors.New("no command provider")
+	}
+}
+
+// DeclarativeCommandProvider loads custom commands declared in the configuration directory.
+//
+// It returns nil if no commands are declared.
+// Custom commands add labels the same way as the builtin commands of the provider (if it supports it).
+func DeclarativeCommandProvider(
+	provider Provider,
+	fsys LazyResult[fs.FS],
+	client *github.Client,
+	logger *slog.Logger,
+) LazyResult[*declarative.CommandProvider] {
+	return func() (*declarative.CommandProvider, error) {
+		fsys, err := fsys.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		config, err := declarative.LoadConfig(fsys)
+		if err != nil {
+			return nil, fmt.Errorf("loading custom commands: %w", err)
+		}
+
+		if len(config.Commands) == 0 {
+			return nil, nil
+		}
+
+		logger.Debug("loaded custom commands", slog.Int("count", len(config.Commands)))
+
+		commandProvider := &declarative.CommandProvider{
+			Config: config,
+			Client: client,
+			Logger: logger,
+		}
+
+		if p, ok := provider.(interface {
+			NewConfiguredAddLabelHandler(
+				client *github.Client,
+				logger *slog.Logger,
+				fsys fs.FS,
+			) (builtin.AddLabelHandler, error)
+		}); ok {
+			handler, err := p.NewConfiguredAddLabelHandler(client, logger, fsys)
+			if err != nil {
+				return nil, err
+			}
+
+			commandProvider.StrictLabels = handler.StrictLabels
+			commandProvider.LabelScopes = handler.Scopes
+		}
+
+		return commandProvider, nil
+	}
+}
+
+func NewExecutionPolicy(provider Provider) octoslash.ExecutionPolicy {
+	switch p := provider.(type) {
+	case interface {
+		ExecutionPolicy() octoslash.ExecutionPolicy
+	}:
+		return p.ExecutionPolicy()
+
+	default:
+		return octoslash.StopOnError
+	}
+}
+
+// NewTrigger returns the trigger set in the configuration or, if it is not set,
+// the trigger configured in the repository (if the provider supports it) or the default trigger of the provider.
+//
+// The repository configuration is only loaded when the trigger is needed (ie. a comment may contain commands).
+func NewTrigger(provider Provider, config Config, fsys LazyResult[fs.FS]) (octoslash.TriggerLoader, error) {
+	if config.Trigger != "" {
+		trigger, err := parser.ParseTrigger(config.Trigger)
+		if err != nil {
+			return nil, err
+		}
+
+		return func() (parser.Trigger, error) { return trigger, nil }, nil
+	}
+
+	trigger := parser.DefaultTrigger
+
+	if p, ok := provider.(interface{ Trigger() parser.Trigger }); ok {
+		trigger = p.Trigger()
+	}
+
+	if p, ok := provider.(interface {
+		NewConfiguredTrigger(fsys fs.FS, fallback parser.Trigger) (parser.Trigger, error)
+	}); ok {
+		return sync.OnceValues(func() (parser.Trigger, error) {
+			fsys, err := fsys.Resolve()
+			if err != nil {
+				return trigger, err
+			}
+
+			return p.NewConfiguredTrigger(fsys, trigger)
+		}), nil
+	}
+
+	return func() (parser.Trigger, error) { return trigger, nil }, nil
+}
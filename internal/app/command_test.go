@@ -0,0 +1,150 @@
+package app
+
+import (
+	"io/fs"
+	"log/slog"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sagikazarmark/octoslash/builtin"
+	"github.com/sagikazarmark/octoslash/declarative"
+	"github.com/sagikazarmark/octoslash/parser"
+)
+
+type triggerProvider struct {
+	builtin.Provider
+
+	trigger parser.Trigger
+}
+
+func (p triggerProvider) Trigger() parser.Trigger {
+	return p.trigger
+}
+
+func TestNewTrigger(t *testing.T) {
+	mention := parser.Trigger{Style: parser.TriggerMention, Name: "octoslash"}
+	namespaced := parser.Trigger{Style: parser.TriggerNamespaced, Name: "bot"}
+
+	configured := fstest.MapFS{
+		builtin.ConfigFileName: &fstest.MapFile{Data: []byte("trigger: /bot\n")},
+	}
+
+	testCases := []struct {
+		name     string
+		provider Provider
+		config   Config
+		fsys     fs.FS
+		trigger  parser.Trigger
+		err      bool
+	}{
+		{
+			name:     "default",
+			provider: struct{}{},
+			trigger:  parser.DefaultTrigger,
+		},
+		{
+			name:     "provider",
+			provider: triggerProvider{trigger: mention},
+			trigger:  mention,
+		},
+		{
+			name:     "repository configuration",
+			provider: triggerProvider{trigger: mention},
+			fsys:     configured,
+			trigger:  namespaced,
+		},
+		{
+			name:     "no repository configuration",
+			provider: builtin.Provider{},
+			fsys:     fstest.MapFS{},
+			trigger:  parser.DefaultTrigger,
+		},
+		{
+			name:     "override",
+			provider: builtin.Provider{},
+			config:   Config{Trigger: "@octoslash"},
+			fsys:     configured,
+			trigger:  mention,
+		},
+		{
+			name:     "invalid repository configuration",
+			provider: builtin.Provider{},
+			fsys: fstest.MapFS{
+				builtin.ConfigFileName: &fstest.MapFile{Data: []byte("trigger: bot\n")},
+			},
+			err: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fsys := func() (fs.FS, error) { return testCase.fsys, nil }
+
+			loadTrigger, err := NewTrigger(testCase.provider, testCase.config, fsys)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			trigger, err := loadTrigger()
+			if testCase.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if trigger != testCase.trigger {
+				t.Errorf("expected trigger %s, got %s", testCase.trigger, trigger)
+			}
+		})
+	}
+}
+
+func TestDeclarativeCommandProvider(t *testing.T) {
+	fsys := fstest.MapFS{
+		builtin.ConfigFileName: &fstest.MapFile{Data: []byte(`
+labels:
+  strict: true
+  scopes:
+    - prefix: priority/
+      exclusive: true
+`)},
+		declarative.ConfigFileName: &fstest.MapFile{Data: []byte(`
+commands:
+  - name: prioritize
+    args:
+      - name: priority
+        required: true
+    actions:
+      - add-labels: ["priority/{{ .Args.priority }}"]
+`)},
+	}
+
+	provider := DeclarativeCommandProvider(
+		builtin.Provider{},
+		func() (fs.FS, error) { return fsys, nil },
+		nil,
+		slog.New(slog.DiscardHandler),
+	)
+
+	commandProvider, err := provider.Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Custom commands add labels the same way as builtin commands
+	if !commandProvider.StrictLabels {
+		t.Error("expected strict labels")
+	}
+
+	expected := []builtin.LabelScope{{Prefix: "priority/", Exclusive: true}}
+	if !slices.Equal(commandProvider.LabelScopes, expected) {
+		t.Errorf("expected label scopes %v, got %v", expected, commandProvider.LabelScopes)
+	}
+}
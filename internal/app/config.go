@@ -0,0 +1,87 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/sagikazarmark/octoslash/audit"
+	"github.com/sagikazarmark/octoslash/logging"
+)
+
+// Config contains settings of the application provided by the runtime (eg. command line flags).
+type Config struct {
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// OpenFile opens files written by the application (eg. audit logs).
+	//
+	// Defaults to [os.OpenFile].
+	OpenFile func(name string, flag int, perm os.FileMode) (*os.File, error)
+
+	// Getenv reads environment variables (eg. OpenTelemetry exporter settings).
+	//
+	// Defaults to [os.Getenv].
+	Getenv func(key string) string
+
+	// ConfigPath is the path of the configuration directory in the repository (defaults to .github/octoslash).
+	ConfigPath string
+
+	// CacheDir is the directory files of the configuration loaded from GitHub are cached in
+	// (caching is disabled if empty).
+	CacheDir string
+
+	// RequireConfig makes a missing configuration directory an error.
+	RequireConfig bool
+
+	// Trigger determines how commands are written in comments (see [parser.ParseTrigger]).
+	//
+	// If empty, the trigger is read from the repository configuration (if the provider supports it)
+	// or the default trigger of the provider is used.
+	Trigger string
+
+	// APIURL is the base URL of the GitHub REST API (eg. https://github.example.com/api/v3 for GitHub Enterprise Server).
+	//
+	// Defaults to https://api.github.com.
+	APIURL string
+
+	// Transport is the base HTTP transport of the default GitHub client (defaults to [http.DefaultTransport]).
+	Transport http.RoundTripper
+
+	// WrapTransport wraps the transport of the GitHub client (eg. to intercept requests).
+	//
+	// Unlike [Config.Transport], it also applies to clients supplied by the provider.
+	WrapTransport func(transport http.RoundTripper) http.RoundTripper
+
+	// DryRun disables side effects that cannot be intercepted by [Config.WrapTransport]
+	// (eg. audit sinks supplied by the provider) when commands are simulated.
+	DryRun bool
+
+	Log   LogConfig
+	Audit AuditConfig
+}
+
+// LogConfig configures the default logger.
+type LogConfig struct {
+	Level  slog.Level
+	Format logging.Format
+}
+
+// AuditConfig configures the built-in audit log sinks.
+type AuditConfig struct {
+	// Sinks are additional audit log sinks.
+	Sinks []audit.Sink
+
+	// JSONLog is the path of a file to append JSON lines audit records to ("-" writes to stdout).
+	JSONLog string
+
+	// StepSummary is the path of the GitHub Actions job summary file.
+	StepSummary string
+
+	// Branch is the name of a branch to commit a Markdown audit log to.
+	Branch string
+
+	// BranchPath is the path of the Markdown audit log on the branch.
+	BranchPath string
+}
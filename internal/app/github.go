@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// DefaultAPIURL is the base URL of the GitHub.com REST API.
+const DefaultAPIURL = "https://api.github.com"
+
+// NewGitHubClient creates a new GitHub client for the REST API at apiURL.
+//
+// If apiURL is empty or points to GitHub.com, the client uses the GitHub.com API.
+// Otherwise (eg. GitHub Enterprise Server) the client is configured with enterprise URLs.
+func NewGitHubClient(httpClient *http.Client, apiURL string) (*github.Client, error) {
+	client := github.NewClient(httpClient)
+
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	if apiURL == "" || apiURL == DefaultAPIURL {
+		return client, nil
+	}
+
+	// GitHub Enterprise Server serves uploads next to the API (/api/v3 -> /api/uploads)
+	uploadURL := apiURL
+	if base, ok := strings.CutSuffix(apiURL, "/api/v3"); ok {
+		uploadURL = base + "/api/uploads"
+	}
+
+	client, err := client.WithEnterpriseURLs(apiURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API URL %q: %w", apiURL, err)
+	}
+
+	return client, nil
+}
+
+// ResolveAPIURL returns the base URL of the GitHub REST API from the values of
+// the GITHUB_API_URL and GITHUB_GRAPHQL_URL environment variables.
+//
+// Octoslash only uses the REST API, but the GraphQL URL is used to derive the REST API URL if it is not set
+// (eg. https://github.example.com/api/graphql -> https://github.example.com/api/v3).
+// It returns an error if the two URLs point to different hosts.
+func ResolveAPIURL(apiURL string, graphQLURL string) (string, error) {
+	if graphQLURL == "" {
+		return apiURL, nil
+	}
+
+	graphQL, err := url.Parse(graphQLURL)
+	if err != nil || graphQL.Host == "" {
+		return "", fmt.Errorf("invalid GitHub GraphQL URL %q", graphQLURL)
+	}
+
+	if apiURL == "" {
+		if base, ok := strings.CutSuffix(strings.TrimSuffix(graphQLURL, "/"), "/api/graphql"); ok {
+			return base + "/api/v3", nil
+		}
+
+		// GitHub.com serves GraphQL at https://api.github.com/graphql
+		return strings.TrimSuffix(strings.TrimSuffix(graphQLURL, "/"), "/graphql"), nil
+	}
+
+	api, err := url.Parse(apiURL)
+	if err != nil || api.Host == "" {
+		return "", fmt.Errorf("invalid GitHub API URL %q", apiURL)
+	}
+
+	if api.Host != graphQL.Host {
+		return "", fmt.Errorf(
+			"GitHub API URL %q and GraphQL URL %q point to different hosts",
+			apiURL,
+			graphQLURL,
+		)
+	}
+
+	return apiURL, nil
+}
+
+// withTransport returns a copy of client with its transport wrapped by wrap.
+//
+// Unlike creating a new client, the copy keeps the settings of client (eg. enterprise URLs).
+func withTransport(
+	client *github.Client,
+	wrap func(transport http.RoundTripper) http.RoundTripper,
+) *github.Client {
+	httpClient := client.Client()
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient.Transport = wrap(transport)
+
+	wrapped := github.NewClient(httpClient)
+	wrapped.BaseURL = client.BaseURL
+	wrapped.UploadURL = client.UploadURL
+	wrapped.UserAgent = client.UserAgent
+
+	return wrapped
+}
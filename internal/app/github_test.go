@@ -0,0 +1,246 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/sagikazarmark/octoslash/builtin"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestNewGitHubClient(t *testing.T) {
+	testCases := []struct {
+		name      string
+		apiURL    string
+		baseURL   string
+		uploadURL string
+		wantErr   bool
+	}{
+		{
+			name:      "default",
+			baseURL:   "https://api.github.com/",
+			uploadURL: "https://uploads.github.com/",
+		},
+		{
+			name:      "github.com",
+			apiURL:    "https://api.github.com/",
+			baseURL:   "https://api.github.com/",
+			uploadURL: "https://uploads.github.com/",
+		},
+		{
+			name:      "enterprise server",
+			apiURL:    "https://github.example.com/api/v3",
+			baseURL:   "https://github.example.com/api/v3/",
+			uploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:      "enterprise server with trailing slash",
+			apiURL:    "https://github.example.com/api/v3/",
+			baseURL:   "https://github.example.com/api/v3/",
+			uploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:      "custom path",
+			apiURL:    "https://proxy.example.com/github",
+			baseURL:   "https://proxy.example.com/github/api/v3/",
+			uploadURL: "https://proxy.example.com/github/api/uploads/",
+		},
+		{
+			name:    "invalid",
+			apiURL:  "://github.example.com",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client, err := NewGitHubClient(nil, testCase.apiURL)
+			if testCase.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual := client.BaseURL.String(); actual != testCase.baseURL {
+				t.Errorf("expected base URL %q, got %q", testCase.baseURL, actual)
+			}
+
+			if actual := client.UploadURL.String(); actual != testCase.uploadURL {
+				t.Errorf("expected upload URL %q, got %q", testCase.uploadURL, actual)
+			}
+		})
+	}
+}
+
+func TestResolveAPIURL(t *testing.T) {
+	testCases := []struct {
+		name       string
+		apiURL     string
+		graphQLURL string
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "api only",
+			apiURL:   "https://github.example.com/api/v3",
+			expected: "https://github.example.com/api/v3",
+		},
+		{
+			name:       "both",
+			apiURL:     "https://github.example.com/api/v3",
+			graphQLURL: "https://github.example.com/api/graphql",
+			expected:   "https://github.example.com/api/v3",
+		},
+		{
+			name:       "derived from enterprise server graphql",
+			graphQLURL: "https://github.example.com/api/graphql",
+			expected:   "https://github.example.com/api/v3",
+		},
+		{
+			name:       "derived from github.com graphql",
+			graphQLURL: "https://api.github.com/graphql",
+			expected:   "https://api.github.com",
+		},
+		{
+			name:       "different hosts",
+			apiURL:     "https://api.github.com",
+			graphQLURL: "https://github.example.com/api/graphql",
+			wantErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := ResolveAPIURL(testCase.apiURL, testCase.graphQLURL)
+			if testCase.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/owner/repo" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Error("expected the request to be authenticated")
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := github.NewClient(nil).WithEnterpriseURLs(server.URL+"/api/v3", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wrapped bool
+
+	client = withTransport(
+		client.WithAuthToken("token"),
+		func(transport http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				wrapped = true
+
+				return transport.RoundTrip(req)
+			})
+		},
+	)
+
+	_, _, err = client.Repositories.Get(t.Context(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !wrapped {
+		t.Error("expected the request to pass through the wrapped transport")
+	}
+}
+
+type clientProvider struct {
+	builtin.Provider
+
+	client *github.Client
+}
+
+func (p clientProvider) NewClient() *github.Client {
+	return p.client
+}
+
+func TestNewClient_Provider(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		// Fail the first request with a transient error
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	providerClient := github.NewClient(nil)
+	providerClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+	client, err := NewClient(
+		clientProvider{client: providerClient},
+		Config{},
+		"",
+		tracenoop.NewTracerProvider(),
+		metricnoop.NewMeterProvider(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = client.Repositories.Get(t.Context(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("expected the transient error to be retried, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// shutdownTimeout limits how long flushing telemetry may delay exiting.
+const shutdownTimeout = 5 * time.Second
+
+// NewTracerProvider returns the tracer provider used to instrument event handling.
+//
+// Unless the provider supplies one, traces are exported using OTLP (over HTTP)
+// if an OTLP endpoint is configured by the standard OpenTelemetry environment variables
+// (read using [Config.Getenv]).
+// Otherwise tracing is disabled.
+func NewTracerProvider(provider Provider, config Config) (trace.TracerProvider, func(), error) {
+	if p, ok := provider.(interface{ NewTracerProvider() trace.TracerProvider }); ok {
+		return p.NewTracerProvider(), func() {}, nil
+	}
+
+	env := newOTLPEnv(config)
+
+	if !env.enabled("TRACES") {
+		return tracenoop.NewTracerProvider(), func() {}, nil
+	}
+
+	ctx := context.Background()
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(env.endpoint("TRACES", "v1/traces"))}
+	if headers := env.headers("TRACES"); len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := newResource(ctx, env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	return tracerProvider, shutdown(tracerProvider.Shutdown), nil
+}
+
+// NewMeterProvider returns the meter provider used to instrument event handling.
+//
+// Unless the provider supplies one, metrics are exported using OTLP (over HTTP)
+// if an OTLP endpoint is configured by the standard OpenTelemetry environment variables
+// (read using [Config.Getenv]).
+// Otherwise metrics are disabled.
+func NewMeterProvider(provider Provider, config Config) (metric.MeterProvider, func(), error) {
+	if p, ok := provider.(interface{ NewMeterProvider() metric.MeterProvider }); ok {
+		return p.NewMeterProvider(), func() {}, nil
+	}
+
+	env := newOTLPEnv(config)
+
+	if !env.enabled("METRICS") {
+		return metricnoop.NewMeterProvider(), func() {}, nil
+	}
+
+	ctx := context.Background()
+
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpointURL(env.endpoint("METRICS", "v1/metrics"))}
+	if headers := env.headers("METRICS"); len(headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(headers))
+	}
+
+	exporter, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := newResource(ctx, env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	return meterProvider, shutdown(meterProvider.Shutdown), nil
+}
+
+// shutdown returns a cleanup function flushing and stopping exporters within [shutdownTimeout].
+func shutdown(fn func(ctx context.Context) error) func() {
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		_ = fn(ctx)
+	}
+}
+
+// otlpEnv reads the standard OpenTelemetry environment variables.
+//
+// The exporters and resources are configured explicitly from them
+// (instead of letting the SDK read the process environment), so they honor [Config.Getenv].
+type otlpEnv func(key string) string
+
+func newOTLPEnv(config Config) otlpEnv {
+	if config.Getenv == nil {
+		return os.Getenv
+	}
+
+	return config.Getenv
+}
+
+// enabled reports whether an OTLP endpoint is configured for a signal (TRACES or METRICS).
+func (env otlpEnv) enabled(signal string) bool {
+	if env("OTEL_SDK_DISABLED") == "true" {
+		return false
+	}
+
+	return env("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || env("OTEL_EXPORTER_OTLP_"+signal+"_ENDPOINT") != ""
+}
+
+// endpoint returns the endpoint URL of a signal.
+//
+// The signal specific endpoint is used as is, while the path of the signal is appended to the generic endpoint.
+func (env otlpEnv) endpoint(signal string, path string) string {
+	if endpoint := env("OTEL_EXPORTER_OTLP_" + signal + "_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+
+	return strings.TrimSuffix(env("OTEL_EXPORTER_OTLP_ENDPOINT"), "/") + "/" + path
+}
+
+// headers returns the headers sent to the endpoint of a signal (the signal specific headers take precedence).
+func (env otlpEnv) headers(signal string) map[string]string {
+	headers := parseKeyValues(env("OTEL_EXPORTER_OTLP_HEADERS"))
+
+	maps.Copy(headers, parseKeyValues(env("OTEL_EXPORTER_OTLP_"+signal+"_HEADERS")))
+
+	return headers
+}
+
+// parseKeyValues parses a list of key-value pairs (eg. key1=value1,key2=value2) with URL encoded values.
+//
+// Invalid pairs are ignored.
+func parseKeyValues(s string) map[string]string {
+	values := make(map[string]string)
+
+	for pair := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		values[strings.TrimSpace(key)] = value
+	}
+
+	return values
+}
+
+func newResource(ctx context.Context, env otlpEnv) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{attribute.String("service.name", "octoslash")}
+
+	// Environment variables take precedence
+	for key, value := range parseKeyValues(env("OTEL_RESOURCE_ATTRIBUTES")) {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	if serviceName := env("OTEL_SERVICE_NAME"); serviceName != "" {
+		attrs = append(attrs, attribute.String("service.name", serviceName))
+	}
+
+	res, err := resource.New(
+		ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attrs...),
+	)
+	if errors.Is(err, resource.ErrPartialResource) {
+		return res, nil
+	}
+
+	return res, err
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests = append(requests, r)
+	}))
+	t.Cleanup(server.Close)
+
+	// Settings are read through the configured function, not the process environment
+	env := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": server.URL + "/otlp/",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "authorization=Bearer%20token",
+	}
+
+	tracerProvider, cleanup, err := NewTracerProvider(nil, Config{
+		Getenv: func(key string) string { return env[key] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := tracerProvider.Tracer("test").Start(t.Context(), "test")
+	span.End()
+
+	// Flushes the spans
+	cleanup()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(requests) != 1 {
+		t.Fatalf("expected exactly one export request, got %d", len(requests))
+	}
+
+	if path := requests[0].URL.Path; path != "/otlp/v1/traces" {
+		t.Errorf("expected spans to be exported to /otlp/v1/traces, got %s", path)
+	}
+
+	if header := requests[0].Header.Get("Authorization"); header != "Bearer token" {
+		t.Errorf("expected the configured headers to be sent, got %q", header)
+	}
+}
+
+func TestNewTracerProvider_Disabled(t *testing.T) {
+	env := map[string]string{
+		"OTEL_SDK_DISABLED":           "true",
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+	}
+
+	tracerProvider, _, err := NewTracerProvider(nil, Config{
+		Getenv: func(key string) string { return env[key] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := tracerProvider.(tracenoop.TracerProvider); !ok {
+		t.Errorf("expected tracing to be disabled, got %T", tracerProvider)
+	}
+}
@@ -4,18 +4,28 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
-	"os"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v74/github"
-	githubfs "github.com/sagikazarmark/go-github-fs"
 	"github.com/wireinject/wire"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/internal/repofs"
+	"github.com/sagikazarmark/octoslash/logging"
+	"github.com/sagikazarmark/octoslash/retry"
 )
 
 type Provider any
@@ -29,11 +39,14 @@ func InitializeEventHandler(
 	token Token,
 	repo *github.Repository,
 	localFS LocalFS,
-) (octoslash.EventHandler, error) {
+	config Config,
+) (octoslash.EventHandler, func(), error) {
 	wire.Build(
 		NewLogger,
 		NewClient,
 		NewFS,
+		NewTracerProvider,
+		NewMeterProvider,
 
 		// Authorization
 		DefaultAuthorizer,
@@ -43,65 +56,159 @@ func InitializeEventHandler(
 		NewCommandDispatcher,
 		DefaultCommandDispatcher,
 		NewCommandProvider,
-
-		wire.Struct(new(octoslash.EventHandler), "*"),
+		DeclarativeCommandProvider,
+		NewExecutionPolicy,
+		NewTrigger,
+		NewAuditSink,
+
+		wire.Struct(
+			new(octoslash.EventHandler),
+			"Dispatcher",
+			"ExecutionPolicy",
+			"LoadTrigger",
+			"TracerProvider",
+			"Logger",
+		),
 	)
 
-	return octoslash.EventHandler{}, nil
+	return octoslash.EventHandler{}, nil, nil
 }
 
-// TODO: inject output
-func NewLogger(provider Provider) *slog.Logger {
+func NewLogger(provider Provider, config Config) (*slog.Logger, error) {
 	switch p := provider.(type) {
 	case interface{ NewLogger() *slog.Logger }:
-		return p.NewLogger()
+		return p.NewLogger(), nil
 
 	case interface{ NewLogger(io.Writer) *slog.Logger }:
-		return p.NewLogger(os.Stderr)
+		return p.NewLogger(config.Stderr), nil
 
 	default:
-		return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		handler, err := logging.NewHandler(
+			config.Stderr,
+			config.Log.Format,
+			&slog.HandlerOptions{Level: config.Log.Level},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return slog.New(handler), nil
 	}
 }
 
-func NewClient(provider Provider, token Token) *github.Client {
+func NewClient(
+	provider Provider,
+	config Config,
+	token Token,
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) (*github.Client, error) {
+	instrument := func(transport http.RoundTripper) http.RoundTripper {
+		if config.WrapTransport != nil {
+			transport = config.WrapTransport(transport)
+		}
+
+		return otelhttp.NewTransport(
+			transport,
+			otelhttp.WithTracerProvider(tracerProvider),
+			otelhttp.WithMeterProvider(meterProvider),
+		)
+	}
+
+	// Clients supplied by the provider are retried as well.
+	// Retry outside of instrumentation, so every attempt is traced.
+	wrap := func(transport http.RoundTripper) http.RoundTripper {
+		return &retry.Transport{
+			Transport: instrument(transport),
+		}
+	}
+
 	switch p := provider.(type) {
 	case interface{ NewClient() *github.Client }:
-		return p.NewClient()
+		return withTransport(p.NewClient(), wrap), nil
 
 	case interface{ NewClient(string) *github.Client }:
-		return p.NewClient(string(token))
+		return withTransport(p.NewClient(string(token)), wrap), nil
 
 	default:
-		client := github.NewClient(nil)
+		transport := config.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+
+		client, err := NewGitHubClient(&http.Client{Transport: wrap(transport)}, config.APIURL)
+		if err != nil {
+			return nil, err
+		}
+
 		if token != "" {
 			client = client.WithAuthToken(string(token))
 		}
 
-		return client
+		return client, nil
 	}
 }
 
-func NewFS(localFS LocalFS, client *github.Client, repo *github.Repository) LazyResult[fs.FS] {
-	return func() (fs.FS, error) {
+// configLoadTimeout limits how long loading configuration from GitHub may take.
+const configLoadTimeout = time.Minute
+
+// NewFS returns the configuration directory.
+//
+// Configuration is loaded (at most) once, no matter how many components use it.
+func NewFS(
+	localFS LocalFS,
+	config Config,
+	client *github.Client,
+	repo *github.Repository,
+) LazyResult[fs.FS] {
+	return sync.OnceValues(func() (fs.FS, error) {
 		if localFS != nil {
 			return localFS, nil
 		}
 
-		githubFS := githubfs.New(
-			githubfs.WithClient(client),
-			githubfs.WithRepository(repo.GetOwner().GetLogin(), repo.GetName()),
-		)
-
 		const defaultConfigPath = ".github/octoslash"
 
-		_, err := fs.Stat(githubFS, defaultConfigPath)
+		configPath := defaultConfigPath
+		if config.ConfigPath != "" {
+			configPath = path.Clean(strings.TrimPrefix(config.ConfigPath, "./"))
+		}
+
+		if !fs.ValidPath(configPath) {
+			return nil, fmt.Errorf(
+				"invalid configuration path %q: must be relative to the repository root",
+				config.ConfigPath,
+			)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), configLoadTimeout)
+		defer cancel()
+
+		loader := repofs.Loader{
+			Client:   client,
+			CacheDir: config.CacheDir,
+		}
+
+		branch, err := loader.DefaultBranch(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("loading octoslash config from GitHub: %w", err)
+		}
+
+		// Every configuration file is read from the tree the default branch points to when it is resolved
+		fsys, err := loader.Load(ctx, repo.GetOwner().GetLogin(), repo.GetName(), branch, configPath)
 		if errors.Is(err, fs.ErrNotExist) {
+			if config.RequireConfig {
+				return nil, fmt.Errorf(
+					"configuration directory %q does not exist in repository %s",
+					configPath,
+					repo.GetFullName(),
+				)
+			}
+
 			return nil, nil
 		} else if err != nil {
-			return nil, fmt.Errorf("opening octoslash config from GitHub Actions: %w", err)
+			return nil, fmt.Errorf("loading octoslash config from GitHub: %w", err)
 		}
 
-		return fs.Sub(githubFS, defaultConfigPath)
-	}
+		return fsys, nil
+	})
 }
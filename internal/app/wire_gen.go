@@ -7,36 +7,91 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/go-github/v74/github"
-	"github.com/sagikazarmark/go-github-fs"
 	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/internal/repofs"
+	"github.com/sagikazarmark/octoslash/logging"
+	"github.com/sagikazarmark/octoslash/retry"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"io/fs"
 	"log/slog"
-	"os"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Injectors from wire.go:
 
-func InitializeEventHandler(provider Provider, token Token, repo *github.Repository, localFS LocalFS) (octoslash.EventHandler, error) {
-	client := NewClient(provider, token)
-	lazyResult := NewFS(localFS, client, repo)
+func InitializeEventHandler(provider Provider, token Token, repo *github.Repository, localFS LocalFS, config Config) (octoslash.EventHandler, func(), error) {
+	tracerProvider, cleanup, err := NewTracerProvider(provider, config)
+	if err != nil {
+		return octoslash.EventHandler{}, nil, err
+	}
+	meterProvider, cleanup2, err := NewMeterProvider(provider, config)
+	if err != nil {
+		cleanup()
+		return octoslash.EventHandler{}, nil, err
+	}
+	client, err := NewClient(provider, config, token, tracerProvider, meterProvider)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return octoslash.EventHandler{}, nil, err
+	}
+	lazyResult := NewFS(localFS, config, client, repo)
 	appLazyResult := DefaultPolicyLoader(lazyResult)
 	lazyResult2 := DefaultEntityLoader(lazyResult)
-	logger := NewLogger(provider)
+	logger, err := NewLogger(provider, config)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return octoslash.EventHandler{}, nil, err
+	}
 	lazyResult3 := DefaultAuthorizer(provider, appLazyResult, lazyResult2, logger)
-	lazyResult4 := NewCommandProvider(provider, client, logger)
-	lazyResult5 := DefaultCommandDispatcher(lazyResult3, lazyResult4)
-	commandDispatcher, err := NewCommandDispatcher(provider, lazyResult5)
+	lazyResult4 := DeclarativeCommandProvider(provider, lazyResult, client, logger)
+	lazyResult5 := NewCommandProvider(provider, client, logger, lazyResult, lazyResult4)
+	sink, cleanup3, err := NewAuditSink(provider, config, client, repo)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return octoslash.EventHandler{}, nil, err
+	}
+	lazyResult6 := DefaultCommandDispatcher(provider, lazyResult3, lazyResult5, sink, tracerProvider, meterProvider, logger)
+	commandDispatcher, err := NewCommandDispatcher(provider, lazyResult6, logger)
 	if err != nil {
-		return octoslash.EventHandler{}, err
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return octoslash.EventHandler{}, nil, err
+	}
+	executionPolicy := NewExecutionPolicy(provider)
+	triggerLoader, err := NewTrigger(provider, config, lazyResult)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return octoslash.EventHandler{}, nil, err
 	}
 	eventHandler := octoslash.EventHandler{
-		Dispatcher: commandDispatcher,
+		Dispatcher:      commandDispatcher,
+		ExecutionPolicy: executionPolicy,
+		LoadTrigger:     triggerLoader,
+		TracerProvider:  tracerProvider,
+		Logger:          logger,
 	}
-	return eventHandler, nil
+	return eventHandler, func() {
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
 }
 
 // wire.go:
@@ -47,55 +102,136 @@ type Token string
 
 type LocalFS = fs.FS
 
-// TODO: inject output
-func NewLogger(provider Provider) *slog.Logger {
+func NewLogger(provider Provider, config Config) (*slog.Logger, error) {
 	switch p := provider.(type) {
 	case interface{ NewLogger() *slog.Logger }:
-		return p.NewLogger()
+		return p.NewLogger(), nil
 
 	case interface{ NewLogger(io.Writer) *slog.Logger }:
-		return p.NewLogger(os.Stderr)
+		return p.NewLogger(config.Stderr), nil
 
 	default:
-		return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		handler, err := logging.NewHandler(
+			config.Stderr,
+			config.Log.Format,
+			&slog.HandlerOptions{Level: config.Log.Level},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return slog.New(handler), nil
 	}
 }
 
-func NewClient(provider Provider, token Token) *github.Client {
+func NewClient(
+	provider Provider,
+	config Config,
+	token Token,
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) (*github.Client, error) {
+	instrument := func(transport http.RoundTripper) http.RoundTripper {
+		if config.WrapTransport != nil {
+			transport = config.WrapTransport(transport)
+		}
+
+		return otelhttp.NewTransport(
+			transport, otelhttp.WithTracerProvider(tracerProvider), otelhttp.WithMeterProvider(meterProvider),
+		)
+	}
+
+	wrap := func(transport http.RoundTripper) http.RoundTripper {
+		return &retry.Transport{
+			Transport: instrument(transport),
+		}
+	}
+
 	switch p := provider.(type) {
 	case interface{ NewClient() *github.Client }:
-		return p.NewClient()
+		return withTransport(p.NewClient(), wrap), nil
 
 	case interface{ NewClient(string) *github.Client }:
-		return p.NewClient(string(token))
+		return withTransport(p.NewClient(string(token)), wrap), nil
 
 	default:
-		client := github.NewClient(nil)
+		transport := config.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+
+		client, err := NewGitHubClient(&http.Client{Transport: wrap(transport)}, config.APIURL)
+		if err != nil {
+			return nil, err
+		}
+
 		if token != "" {
 			client = client.WithAuthToken(string(token))
 		}
 
-		return client
+		return client, nil
 	}
 }
 
-func NewFS(localFS LocalFS, client *github.Client, repo *github.Repository) LazyResult[fs.FS] {
-	return func() (fs.FS, error) {
+// configLoadTimeout limits how long loading configuration from GitHub may take.
+const configLoadTimeout = time.Minute
+
+// NewFS returns the configuration directory.
+//
+// Configuration is loaded (at most) once, no matter how many components use it.
+func NewFS(
+	localFS LocalFS,
+	config Config,
+	client *github.Client,
+	repo *github.Repository,
+) LazyResult[fs.FS] {
+	return sync.OnceValues(func() (fs.FS, error) {
 		if localFS != nil {
 			return localFS, nil
 		}
 
-		githubFS := githubfs.New(githubfs.WithClient(client), githubfs.WithRepository(repo.GetOwner().GetLogin(), repo.GetName()))
-
 		const defaultConfigPath = ".github/octoslash"
 
-		_, err := fs.Stat(githubFS, defaultConfigPath)
+		configPath := defaultConfigPath
+		if config.ConfigPath != "" {
+			configPath = path.Clean(strings.TrimPrefix(config.ConfigPath, "./"))
+		}
+
+		if !fs.ValidPath(configPath) {
+			return nil, fmt.Errorf(
+				"invalid configuration path %q: must be relative to the repository root",
+				config.ConfigPath,
+			)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), configLoadTimeout)
+		defer cancel()
+
+		loader := repofs.Loader{
+			Client:   client,
+			CacheDir: config.CacheDir,
+		}
+
+		branch, err := loader.DefaultBranch(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("loading octoslash config from GitHub: %w", err)
+		}
+
+		fsys, err := loader.Load(ctx, repo.GetOwner().GetLogin(), repo.GetName(), branch, configPath)
 		if errors.Is(err, fs.ErrNotExist) {
+			if config.RequireConfig {
+				return nil, fmt.Errorf(
+					"configuration directory %q does not exist in repository %s",
+					configPath,
+					repo.GetFullName(),
+				)
+			}
+
 			return nil, nil
 		} else if err != nil {
-			return nil, fmt.Errorf("opening octoslash config from GitHub Actions: %w", err)
+			return nil, fmt.Errorf("loading octoslash config from GitHub: %w", err)
 		}
 
-		return fs.Sub(githubFS, defaultConfigPath)
-	}
+		return fsys, nil
+	})
 }
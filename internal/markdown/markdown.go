@@ -0,0 +1,60 @@
+// Package markdown escapes untrusted text (eg. comments) written to Markdown tables.
+package markdown
+
+import (
+	"strings"
+)
+
+// Escape escapes text in a Markdown table cell.
+//
+// Every ASCII punctuation character is escaped with a backslash,
+// so the text cannot contain formatting, links, images, HTML or mentions.
+// Line breaks are left as is.
+func Escape(text string) string {
+	var b strings.Builder
+
+	for _, r := range text {
+		if r < 0x80 && isPunct(byte(r)) {
+			b.WriteByte('\\')
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// CodeSpan returns text as an inline code span in a Markdown table cell.
+//
+// The delimiter is longer than any run of backticks in the text, so the text cannot close the span.
+// Pipes are escaped (they would end the table cell even in a code span) and line breaks are replaced with spaces.
+func CodeSpan(text string) string {
+	text = strings.NewReplacer("|", `\|`, "\r", "", "\n", " ").Replace(text)
+
+	var longest, run int
+
+	for i := range len(text) {
+		if text[i] != '`' {
+			run = 0
+
+			continue
+		}
+
+		run++
+		longest = max(longest, run)
+	}
+
+	delimiter := strings.Repeat("`", longest+1)
+
+	// A space separates backticks at the edges from the delimiter (it is stripped when rendering)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+
+	return delimiter + text + delimiter
+}
+
+// isPunct reports whether c is an ASCII punctuation character (these can be escaped in Markdown).
+func isPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
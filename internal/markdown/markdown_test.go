@@ -0,0 +1,45 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/sagikazarmark/octoslash/internal/markdown"
+)
+
+func TestEscape(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{text: "plain text", expected: "plain text"},
+		{text: "[link](https://example.com)", expected: `\[link\]\(https\:\/\/example\.com\)`},
+		{text: "![image](x.png) <img> @alice", expected: `\!\[image\]\(x\.png\) \<img\> \@alice`},
+		{text: "a|b\nc", expected: "a\\|b\nc"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := markdown.Escape(testCase.text); actual != testCase.expected {
+			t.Errorf("Escape(%q) = %q, expected %q", testCase.text, actual, testCase.expected)
+		}
+	}
+}
+
+func TestCodeSpan(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{text: "/label bug", expected: "`/label bug`"},
+		{text: "label a|b", expected: "`label a\\|b`"},
+		{text: "label `x` [y](z)", expected: "``label `x` [y](z)``"},
+		{text: "a ``` b", expected: "````a ``` b````"},
+		{text: "`x", expected: "`` `x ``"},
+		{text: "line 1\nline 2", expected: "`line 1 line 2`"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := markdown.CodeSpan(testCase.text); actual != testCase.expected {
+			t.Errorf("CodeSpan(%q) = %q, expected %q", testCase.text, actual, testCase.expected)
+		}
+	}
+}
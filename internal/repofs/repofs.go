@@ -0,0 +1,347 @@
+// Package repofs loads directories of GitHub repositories pinned to a tree.
+package repofs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing/fstest"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Loader loads directories of GitHub repositories.
+//
+// The tree of the directory is resolved by walking the path with non-recursive Git tree lookups.
+// Trees are content-addressed, so every file is read from the same snapshot, even if the ref changes in the meantime.
+//
+// Directories are cached on disk by tree SHA (if enabled): a cache hit requires no further API calls.
+// Cached trees are verified by recomputing their SHA, so tampered cache entries are never used.
+// Otherwise the files of the directory are listed and their blobs are downloaded one by one
+// (every file is verified against the SHA of its blob).
+type Loader struct {
+	Client *github.Client
+
+	// CacheDir is the directory downloaded directories are cached in.
+	//
+	// Caching is disabled if empty.
+	CacheDir string
+}
+
+// DefaultBranch returns the name of the default branch of a repository.
+//
+// The repository is only looked up if the default branch is not set.
+func (l Loader) DefaultBranch(ctx context.Context, repo *github.Repository) (string, error) {
+	if branch := repo.GetDefaultBranch(); branch != "" {
+		return branch, nil
+	}
+
+	r, _, err := l.Client.Repositories.Get(ctx, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return "", fmt.Errorf("getting repository: %w", err)
+	}
+
+	return r.GetDefaultBranch(), nil
+}
+
+// Load returns the contents of dir in a repository at the given ref (eg. a branch or a commit SHA).
+//
+// It returns an error wrapping [fs.ErrNotExist] if dir does not exist at the ref.
+func (l Loader) Load(
+	ctx context.Context,
+	owner string,
+	repo string,
+	ref string,
+	dir string,
+) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "load", Path: dir, Err: fs.ErrInvalid}
+	}
+
+	sha, err := l.resolveTree(ctx, owner, repo, ref, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var cachePath string
+
+	if l.CacheDir != "" {
+		cachePath = filepath.Join(l.CacheDir, "trees", sha+".json")
+
+		// Cached trees are verified as well: anyone with access to the cache could have changed them
+		if data, err := os.ReadFile(cachePath); err == nil {
+			var entries []treeEntry
+
+			if json.Unmarshal(data, &entries) == nil && verify(entries, sha) == nil {
+				return newFS(entries), nil
+			}
+		}
+	}
+
+	entries, err := l.download(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", dir, err)
+	}
+
+	if err := verify(entries, sha); err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", dir, err)
+	}
+
+	if cachePath != "" {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return nil, fmt.Errorf("caching %s: %w", dir, err)
+		}
+
+		if err := writeCache(cachePath, data); err != nil {
+			return nil, fmt.Errorf("caching %s: %w", dir, err)
+		}
+	}
+
+	return newFS(entries), nil
+}
+
+// resolveTree returns the SHA of the tree of dir at a ref.
+//
+// The path is walked one directory at a time (without listing the whole repository).
+func (l Loader) resolveTree(ctx context.Context, owner string, repo string, ref string, dir string) (string, error) {
+	if dir == "." {
+		tree, _, err := l.Client.Git.GetTree(ctx, owner, repo, ref, false)
+		if err != nil {
+			return "", fmt.Errorf("listing files: %w", err)
+		}
+
+		return tree.GetSHA(), nil
+	}
+
+	// The root tree can be looked up by the ref itself
+	sha := ref
+
+	for name := range strings.SplitSeq(dir, "/") {
+		tree, _, err := l.Client.Git.GetTree(ctx, owner, repo, sha, false)
+		if err != nil {
+			return "", fmt.Errorf("listing files: %w", err)
+		}
+
+		i := slices.IndexFunc(tree.Entries, func(entry *github.TreeEntry) bool {
+			return entry.GetPath() == name && entry.GetType() == "tree"
+		})
+		if i < 0 {
+			return "", &fs.PathError{Op: "load", Path: dir, Err: fs.ErrNotExist}
+		}
+
+		sha = tree.Entries[i].GetSHA()
+	}
+
+	return sha, nil
+}
+
+// treeEntry is an entry of a (recursively listed) tree.
+//
+// Data contains the content of regular files.
+type treeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// isFile reports whether the entry is a regular file (symlinks and submodules are skipped).
+func (e treeEntry) isFile() bool {
+	return e.Type == "blob" && e.Mode != "120000"
+}
+
+// download lists a tree recursively and downloads the blobs of its files.
+func (l Loader) download(ctx context.Context, owner string, repo string, sha string) ([]treeEntry, error) {
+	tree, _, err := l.Client.Git.GetTree(ctx, owner, repo, sha, true)
+	if err != nil {
+		return nil, fmt.Errorf("listing files: %w", err)
+	}
+
+	if tree.GetTruncated() {
+		return nil, fmt.Errorf("listing files: tree %s is too large to be listed in a single request", sha)
+	}
+
+	entries := make([]treeEntry, 0, len(tree.Entries))
+
+	for _, e := range tree.Entries {
+		entry := treeEntry{
+			Path: e.GetPath(),
+			Mode: e.GetMode(),
+			Type: e.GetType(),
+			SHA:  e.GetSHA(),
+		}
+
+		if entry.isFile() {
+			entry.Data, _, err = l.Client.Git.GetBlobRaw(ctx, owner, repo, entry.SHA)
+			if err != nil {
+				return nil, fmt.Errorf("downloading %s: %w", entry.Path, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// verify checks that entries make up the tree with the given SHA
+// and that the content of every file matches the SHA of its blob.
+func verify(entries []treeEntry, sha string) error {
+	for _, entry := range entries {
+		if !entry.isFile() {
+			continue
+		}
+
+		if actual := blobSHA(entry.Data); actual != entry.SHA {
+			return fmt.Errorf("file %s: blob SHA mismatch: expected %s, got %s", entry.Path, entry.SHA, actual)
+		}
+	}
+
+	actual, err := treeSHA(entries, ".")
+	if err != nil {
+		return err
+	}
+
+	if actual != sha {
+		return fmt.Errorf("tree SHA mismatch: expected %s, got %s", sha, actual)
+	}
+
+	return nil
+}
+
+// treeSHA computes the Git object ID of the tree of dir from the entries of a recursive listing
+// (subtrees are computed as well, their listed SHAs are not trusted).
+func treeSHA(entries []treeEntry, dir string) (string, error) {
+	type child struct {
+		name string
+		mode string
+		sha  []byte
+	}
+
+	var children []child
+
+	for _, entry := range entries {
+		if path.Dir(entry.Path) != dir {
+			continue
+		}
+
+		sha := entry.SHA
+
+		if entry.Type == "tree" {
+			var err error
+
+			sha, err = treeSHA(entries, entry.Path)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		raw, err := hex.DecodeString(sha)
+		if err != nil || len(raw) != sha1.Size {
+			return "", fmt.Errorf("invalid SHA of %s: %q", entry.Path, sha)
+		}
+
+		children = append(children, child{
+			name: path.Base(entry.Path),
+			// Git writes tree modes without a leading zero
+			mode: strings.TrimLeft(entry.Mode, "0"),
+			sha:  raw,
+		})
+	}
+
+	// Git sorts entries by name, as if the names of trees ended with a slash
+	sortName := func(c child) string {
+		if c.mode == "40000" {
+			return c.name + "/"
+		}
+
+		return c.name
+	}
+
+	slices.SortFunc(children, func(a, b child) int {
+		return strings.Compare(sortName(a), sortName(b))
+	})
+
+	var content bytes.Buffer
+
+	for _, c := range children {
+		fmt.Fprintf(&content, "%s %s\x00", c.mode, c.name)
+		content.Write(c.sha)
+	}
+
+	h := sha1.New()
+
+	fmt.Fprintf(h, "tree %d\x00", content.Len())
+	h.Write(content.Bytes())
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newFS returns the files of a tree.
+func newFS(entries []treeEntry) fs.FS {
+	files := fstest.MapFS{}
+
+	for _, entry := range entries {
+		if !entry.isFile() {
+			continue
+		}
+
+		files[entry.Path] = &fstest.MapFile{
+			Data: entry.Data,
+			Mode: 0o644,
+		}
+	}
+
+	return files
+}
+
+// blobSHA returns the Git object ID of a blob.
+func blobSHA(data []byte) string {
+	h := sha1.New()
+
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCache writes a file to the cache.
+//
+// The file is written to a temporary file first, then renamed, so concurrent readers never see partial content.
+func writeCache(cachePath string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(cachePath), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(cachePath), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), cachePath)
+}
@@ -0,0 +1,152 @@
+package repofs_test
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/internal/repofs"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+func blobSHA(content string) string {
+	return fmt.Sprintf("%x", sha1.Sum(fmt.Appendf(nil, "blob %d\x00%s", len(content), content)))
+}
+
+func TestLoader(t *testing.T) {
+	server := octoslashtest.NewServer(t)
+
+	server.AddRepository(&octoslashtest.Repository{
+		Owner: "owner",
+		Name:  "repo",
+		Files: map[string]string{
+			"README.md":                                "# Repo",
+			"docs/index.md":                            "# Docs",
+			".github/octoslash/principals.json":        "[]",
+			".github/octoslash/policies/default.cedar": "permit(principal, action, resource);",
+		},
+	})
+
+	cacheDir := t.TempDir()
+
+	loader := repofs.Loader{
+		Client:   server.Client(),
+		CacheDir: cacheDir,
+	}
+
+	branch, err := loader.DefaultBranch(t.Context(), &github.Repository{
+		Owner: &github.User{Login: github.Ptr("owner")},
+		Name:  github.Ptr("repo"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if branch != "main" {
+		t.Fatalf("expected branch %q, got %q", "main", branch)
+	}
+
+	// requests counts the requests since the last call (and checks that the repository is never listed recursively)
+	var seen int
+
+	requests := func() (trees int, blobs int) {
+		all := server.Requests()
+
+		for _, request := range all[seen:] {
+			switch {
+			case strings.Contains(request.Path, "/git/blobs/"):
+				blobs++
+
+			case strings.Contains(request.Path, "/git/trees/"):
+				trees++
+
+				if strings.Contains(request.Query, "recursive") && strings.HasSuffix(request.Path, "/git/trees/main") {
+					t.Errorf("unexpected recursive listing of the repository: %s", request)
+				}
+			}
+		}
+
+		seen = len(all)
+
+		return trees, blobs
+	}
+
+	requests()
+
+	load := func() {
+		t.Helper()
+
+		fsys, err := loader.Load(t.Context(), "owner", "repo", branch, ".github/octoslash")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = fstest.TestFS(fsys, "principals.json", "policies/default.cedar")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fs.Stat(fsys, "README.md"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected files outside of the directory to be excluded, got %v", err)
+		}
+	}
+
+	// Walk the path (2 trees), list the directory (1 tree) and download its files (2 blobs)
+	load()
+
+	if trees, blobs := requests(); trees != 3 || blobs != 2 {
+		t.Errorf("expected 3 tree and 2 blob requests, got %d and %d", trees, blobs)
+	}
+
+	// The second load is served from the cache once the path is walked
+	load()
+
+	if trees, blobs := requests(); trees != 2 || blobs != 0 {
+		t.Errorf("expected 2 tree and no blob requests, got %d and %d", trees, blobs)
+	}
+
+	// Tampered cache entries are downloaded again
+	cacheFiles, err := filepath.Glob(filepath.Join(cacheDir, "trees", "*.json"))
+	if err != nil || len(cacheFiles) != 1 {
+		t.Fatalf("expected the directory to be cached, got %v (%v)", cacheFiles, err)
+	}
+
+	cached, err := os.ReadFile(cacheFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both the content and the SHA of the file are changed, so only the tree SHA reveals the change
+	tampered := strings.NewReplacer(
+		base64.StdEncoding.EncodeToString([]byte("[]")), base64.StdEncoding.EncodeToString([]byte("[{}]")),
+		blobSHA("[]"), blobSHA("[{}]"),
+	).Replace(string(cached))
+
+	if tampered == string(cached) {
+		t.Fatal("failed to tamper with the cache")
+	}
+
+	if err := os.WriteFile(cacheFiles[0], []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	load()
+
+	if _, blobs := requests(); blobs != 2 {
+		t.Errorf("expected the files to be downloaded again, got %d blob requests", blobs)
+	}
+
+	_, err = loader.Load(t.Context(), "owner", "repo", branch, ".github/missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+}
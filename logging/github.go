@@ -0,0 +1,158 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// GitHubHandler writes logs as GitHub Actions workflow commands.
+//
+// Debug, warning and error logs are written as ::debug::, ::warning:: and ::error:: commands respectively,
+// so they are rendered (and annotated) properly in the Actions UI. Info logs are written as plain lines.
+//
+// See https://docs.github.com/en/actions/reference/workflows-and-actions/workflow-commands
+type GitHubHandler struct {
+	state *githubHandlerState
+
+	// attrs formats the attributes of records
+	attrs slog.Handler
+	level slog.Leveler
+}
+
+type githubHandlerState struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewGitHubHandler returns a new [GitHubHandler].
+func NewGitHubHandler(w io.Writer, opts *slog.HandlerOptions) *GitHubHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+
+	state := &githubHandlerState{
+		w: w,
+	}
+
+	return &GitHubHandler{
+		state: state,
+		attrs: slog.NewTextHandler(&state.buf, &slog.HandlerOptions{
+			Level:       slog.LevelDebug,
+			AddSource:   opts.AddSource,
+			ReplaceAttr: replaceBuiltinAttrs(opts.ReplaceAttr),
+		}),
+		level: opts.Level,
+	}
+}
+
+// replaceBuiltinAttrs removes the time, level and message from the formatted attributes.
+func replaceBuiltinAttrs(
+	replace func([]string, slog.Attr) slog.Attr,
+) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.MessageKey:
+				return slog.Attr{}
+			}
+		}
+
+		if replace != nil {
+			return replace(groups, a)
+		}
+
+		return a
+	}
+}
+
+// Enabled implements [slog.Handler].
+func (h *GitHubHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.level != nil {
+		minLevel = h.level.Level()
+	}
+
+	return level >= minLevel
+}
+
+// Handle implements [slog.Handler].
+func (h *GitHubHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	h.state.buf.Reset()
+
+	err := h.attrs.Handle(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	message := record.Message
+	if attrs := strings.TrimSpace(h.state.buf.String()); attrs != "" {
+		message += " " + attrs
+	}
+
+	var line string
+
+	switch {
+	case record.Level >= slog.LevelError:
+		line = "::error::" + escapeData(message)
+
+	case record.Level >= slog.LevelWarn:
+		line = "::warning::" + escapeData(message)
+
+	case record.Level >= slog.LevelInfo:
+		line = message
+
+	default:
+		line = "::debug::" + escapeData(message)
+	}
+
+	_, err = io.WriteString(h.state.w, line+"\n")
+
+	return err
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *GitHubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &GitHubHandler{
+		state: h.state,
+		attrs: h.attrs.WithAttrs(attrs),
+		level: h.level,
+	}
+}
+
+// WithGroup implements [slog.Handler].
+func (h *GitHubHandler) WithGroup(name string) slog.Handler {
+	return &GitHubHandler{
+		state: h.state,
+		attrs: h.attrs.WithGroup(name),
+		level: h.level,
+	}
+}
+
+// StartGroup implements [Grouper].
+func (h *GitHubHandler) StartGroup(name string) {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	_, _ = io.WriteString(h.state.w, "::group::"+escapeData(name)+"\n")
+}
+
+// EndGroup implements [Grouper].
+func (h *GitHubHandler) EndGroup() {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	_, _ = io.WriteString(h.state.w, "::endgroup::\n")
+}
+
+// escapeData escapes the data of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
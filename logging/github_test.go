@@ -0,0 +1,49 @@
+package logging_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/sagikazarmark/octoslash/logging"
+)
+
+func TestGitHubHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(logging.NewGitHubHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger = logger.With(slog.String("command", "/label bug"))
+
+	logger.Debug("parsing command")
+
+	end := logging.StartGroup(logger, "/label bug")
+	logger.Info("running command")
+	logger.Warn("label not found", slog.String("label", "bug"))
+	end()
+
+	logger.Error("command failed\nsecond line", slog.Int("status", 100))
+
+	expected := `::debug::parsing command command="/label bug"
+::group::/label bug
+running command command="/label bug"
+::warning::label not found command="/label bug" label=bug
+::endgroup::
+::error::command failed%0Asecond line command="/label bug" status=100
+`
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("unexpected output\nexpected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestGitHubHandler_Level(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(logging.NewGitHubHandler(&buf, nil))
+
+	logger.Debug("hidden")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected debug logs to be discarded by default, got %q", buf.String())
+	}
+}
@@ -0,0 +1,70 @@
+// Package logging provides log handlers for the environments octoslash runs in.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Format is the output format of logs.
+type Format string
+
+const (
+	// FormatText writes logs using [slog.TextHandler].
+	FormatText Format = "text"
+
+	// FormatJSON writes logs using [slog.JSONHandler].
+	FormatJSON Format = "json"
+
+	// FormatGitHub writes logs as GitHub Actions workflow commands using [GitHubHandler].
+	FormatGitHub Format = "github"
+)
+
+// ParseFormat parses a log format.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatText, FormatJSON, FormatGitHub:
+		return format, nil
+
+	default:
+		return "", fmt.Errorf("unknown log format %q (valid formats: text, json, github)", s)
+	}
+}
+
+// NewHandler returns a new [slog.Handler] writing logs to w in the given format.
+func NewHandler(w io.Writer, format Format, opts *slog.HandlerOptions) (slog.Handler, error) {
+	switch format {
+	case FormatText, "":
+		return slog.NewTextHandler(w, opts), nil
+
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+
+	case FormatGitHub:
+		return NewGitHubHandler(w, opts), nil
+
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+// Grouper is implemented by handlers that can visually group log lines (eg. [GitHubHandler]).
+type Grouper interface {
+	StartGroup(name string)
+	EndGroup()
+}
+
+// StartGroup starts a group of log lines if the handler of logger supports it (see [Grouper]).
+//
+// The returned function ends the group.
+func StartGroup(logger *slog.Logger, name string) func() {
+	grouper, ok := logger.Handler().(Grouper)
+	if !ok {
+		return func() {}
+	}
+
+	grouper.StartGroup(name)
+
+	return grouper.EndGroup
+}
@@ -0,0 +1,142 @@
+package octoslash
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// CommandDispatcherFunc is an adapter to allow the use of ordinary functions as a [CommandDispatcher].
+type CommandDispatcherFunc func(ctx context.Context, event github.IssueCommentEvent, args []string) error
+
+// Dispatch calls fn(ctx, event, args).
+func (fn CommandDispatcherFunc) Dispatch(
+	ctx context.Context,
+	event github.IssueCommentEvent,
+	args []string,
+) error {
+	return fn(ctx, event, args)
+}
+
+// Middleware wraps a [CommandDispatcher].
+type Middleware func(next CommandDispatcher) CommandDispatcher
+
+// Chain wraps a dispatcher with a list of middleware.
+//
+// The first middleware is the outermost one.
+//
+// If dispatcher implements [CommandValidator] or [CommandRejecter], so does the returned dispatcher:
+// validation and rejection bypass the middleware.
+func Chain(dispatcher CommandDispatcher, middleware ...Middleware) CommandDispatcher {
+	validator, canValidate := dispatcher.(CommandValidator)
+	rejecter, canReject := dispatcher.(CommandRejecter)
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		dispatcher = middleware[i](dispatcher)
+	}
+
+	switch {
+	case canValidate && canReject:
+		return validatingRejectingDispatcher{
+			CommandDispatcher: dispatcher,
+			CommandValidator:  validator,
+			CommandRejecter:   rejecter,
+		}
+
+	case canValidate:
+		return validatingDispatcher{
+			CommandDispatcher: dispatcher,
+			CommandValidator:  validator,
+		}
+
+	case canReject:
+		return rejectingDispatcher{
+			CommandDispatcher: dispatcher,
+			CommandRejecter:   rejecter,
+		}
+
+	default:
+		return dispatcher
+	}
+}
+
+type validatingDispatcher struct {
+	CommandDispatcher
+	CommandValidator
+}
+
+type rejectingDispatcher struct {
+	CommandDispatcher
+	CommandRejecter
+}
+
+type validatingRejectingDispatcher struct {
+	CommandDispatcher
+	CommandValidator
+	CommandRejecter
+}
+
+// PanicError is returned by [RecoverMiddleware] when dispatching a command panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// RecoverMiddleware converts panics during dispatching a command to a [PanicError].
+func RecoverMiddleware() Middleware {
+	return func(next CommandDispatcher) CommandDispatcher {
+		return CommandDispatcherFunc(func(
+			ctx context.Context,
+			event github.IssueCommentEvent,
+			args []string,
+		) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					err = &PanicError{
+						Value: v,
+						Stack: debug.Stack(),
+					}
+				}
+			}()
+
+			return next.Dispatch(ctx, event, args)
+		})
+	}
+}
+
+// LoggerMiddleware logs the outcome and duration of dispatching a command.
+func LoggerMiddleware(logger *slog.Logger) Middleware {
+	return func(next CommandDispatcher) CommandDispatcher {
+		return CommandDispatcherFunc(func(
+			ctx context.Context,
+			event github.IssueCommentEvent,
+			args []string,
+		) error {
+			start := time.Now()
+
+			err := next.Dispatch(ctx, event, args)
+
+			attrs := []any{
+				slog.String("command", strings.Join(args, " ")),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			if err != nil {
+				logger.ErrorContext(ctx, "command failed", append(attrs, slog.Any("error", err))...)
+			} else {
+				logger.InfoContext(ctx, "command executed", attrs...)
+			}
+
+			return err
+		})
+	}
+}
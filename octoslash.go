@@ -2,60 +2,258 @@ package octoslash
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 
 	"github.com/google/go-github/v74/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/logging"
 	"github.com/sagikazarmark/octoslash/parser"
+	"github.com/sagikazarmark/octoslash/telemetry"
 )
 
 type EventHandler struct {
 	Dispatcher CommandDispatcher
-	// ErrorHandler ErrorHandler
+
+	// ExecutionPolicy controls how failing commands affect the rest of the commands in a comment.
+	ExecutionPolicy ExecutionPolicy
+
+	// Trigger determines how commands are written in comments (defaults to plain slash commands).
+	Trigger parser.Trigger
+
+	// LoadTrigger loads the trigger when a comment is handled (eg. from the repository configuration).
+	// If set, it takes precedence over Trigger.
+	//
+	// It is only called for comments that may contain commands (see [parser.MayContainCommands]).
+	LoadTrigger TriggerLoader
+
+	// TracerProvider is used to trace handling events (tracing is disabled if nil).
+	TracerProvider trace.TracerProvider
+
+	// Logger is used to log handling events (defaults to [slog.Default]).
+	Logger *slog.Logger
 }
 
+// TriggerLoader loads the trigger commands are written with.
+type TriggerLoader func() (parser.Trigger, error)
+
 type CommandDispatcher interface {
 	Dispatch(ctx context.Context, event github.IssueCommentEvent, args []string) error
 }
 
-// Error handling behavior: ignore (debug log), warnButIgnore (error log), return (fails the command)
-// TODO: wait for other commands?
-type ErrorHandler interface {
-	Handle(ctx context.Context, err error)
+// CommandValidator is implemented by dispatchers that can check a command without executing it
+// (eg. resolve it, validate its arguments and authorize it).
+//
+// [EventHandler] uses it to validate every command before executing any of them with the [AllOrNothing] policy.
+// Dispatchers that do not implement it cannot be used with that policy.
+type CommandValidator interface {
+	Validate(ctx context.Context, event github.IssueCommentEvent, args []string) error
+}
+
+// CommandRejecter is implemented by dispatchers that observe commands rejected before dispatching them
+// (eg. to record them in an audit log).
+//
+// [EventHandler] calls it for every command that cannot be parsed, regardless of the [ExecutionPolicy].
+type CommandRejecter interface {
+	Reject(ctx context.Context, event github.IssueCommentEvent, command string, err error)
 }
 
+// Handle executes the commands found in a comment according to the configured [ExecutionPolicy].
+//
+// It returns the errors of every failed command joined together.
+// Use [EventHandler.Execute] to get the outcome of every command.
 func (h EventHandler) Handle(ctx context.Context, event github.IssueCommentEvent) error {
-	logger := slog.Default()
+	_, err := h.Execute(ctx, event)
+
+	return err
+}
+
+// Execute executes the commands found in a comment according to the configured [ExecutionPolicy].
+//
+// It returns the outcome of every command and the errors of every failed command joined together.
+//
+// Commands that cannot be parsed are logged, reported as invalid and included in the returned error,
+// but they do not stop the rest of the commands, unless the execution policy is [AllOrNothing].
+func (h EventHandler) Execute(ctx context.Context, event github.IssueCommentEvent) (Results, error) {
+	logger := h.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	tracer := h.tracer()
+
+	ctx, span := tracer.Start(ctx, "handle")
+	defer span.End()
 
-	rawCommands := parser.ScanString(event.GetComment().GetBody())
+	body := event.GetComment().GetBody()
+
+	trigger := h.Trigger
+
+	if h.LoadTrigger != nil && parser.MayContainCommands(body) {
+		var err error
+
+		trigger, err = h.LoadTrigger()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, fmt.Errorf("loading trigger: %w", err)
+		}
+	}
+
+	_, scanSpan := tracer.Start(ctx, "scan")
+	rawCommands := trigger.Scan(strings.NewReader(body))
+	scanSpan.SetAttributes(attribute.Int("octoslash.commands", len(rawCommands)))
+	scanSpan.End()
 
 	if len(rawCommands) == 0 {
 		logger.Info("no commands to run")
 
-		return nil
+		return nil, nil
 	}
 
 	p := parser.NewParser()
 
-	for _, rawCommand := range rawCommands {
-		args, err := p.Parse(strings.NewReader(rawCommand))
+	results := make(Results, len(rawCommands))
+
+	// Commands that could not be parsed
+	unparsed := make(map[int]bool)
+
+	for i, rawCommand := range rawCommands {
+		results[i].Command = rawCommand.Text
+
+		_, parseSpan := tracer.Start(ctx, "parse")
+		args, err := p.ParseCommand(rawCommand)
+		if err != nil {
+			parseSpan.RecordError(err)
+			parseSpan.SetStatus(codes.Error, err.Error())
+		}
+		parseSpan.End()
+
 		if err != nil {
 			logger.Error(
 				fmt.Sprintf("parsing command: %s", err.Error()),
-				slog.String("command", rawCommand),
+				slog.String("command", rawCommand.Text),
 			)
 
-			// TODO: make this behavior configurable
+			results[i].Status = ResultInvalid
+			results[i].Err = err
+			unparsed[i] = true
+
+			if rejecter, ok := h.Dispatcher.(CommandRejecter); ok {
+				rejecter.Reject(ctx, event, rawCommand.Text, err)
+			}
+
 			continue
 		}
 
-		logger.Debug("running command", slog.String("command", rawCommand))
+		results[i].Args = args
+	}
+
+	if h.ExecutionPolicy == AllOrNothing {
+		if err := h.validate(ctx, event, results); err != nil {
+			logger.Error("not running any commands", slog.Any("error", err))
+
+			results.skip(0)
+
+			return results, err
+		}
+
+		if results.Err() != nil {
+			logger.Error("not running any commands: some commands are invalid")
+
+			results.skip(0)
+
+			return results, results.Err()
+		}
+	}
+
+	for i := range results {
+		result := &results[i]
+
+		if result.Status != ResultInvalid {
+			endGroup := logging.StartGroup(logger, result.Command)
+
+			logger.Debug("running command", slog.String("command", result.Command))
+
+			ctx, decision := command.WithDecisionRecorder(ctx)
+			ctx, name := command.WithCommandNameRecorder(ctx)
+
+			err := h.Dispatcher.Dispatch(ctx, event, result.Args)
+			result.Decision = decision()
+			result.setName(name())
+
+			endGroup()
+			if err != nil {
+				result.Status = failureStatus(err, ResultFailed)
+				result.Err = err
+			} else {
+				result.Status = ResultSucceeded
+			}
+		}
+
+		if result.Err != nil && !unparsed[i] && h.ExecutionPolicy != ContinueOnError {
+			results.skip(i + 1)
+
+			break
+		}
+	}
+
+	// Commands that could not be parsed fail the comment as well (even if they did not stop the rest)
+	var errs []error
+
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.err())
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (h EventHandler) tracer() trace.Tracer {
+	if h.TracerProvider == nil {
+		return noop.NewTracerProvider().Tracer("")
+	}
+
+	return h.TracerProvider.Tracer(telemetry.InstrumentationName)
+}
+
+// validate checks every parsed command (if the dispatcher supports validation) and marks invalid ones.
+func (h EventHandler) validate(
+	ctx context.Context,
+	event github.IssueCommentEvent,
+	results Results,
+) error {
+	validator, ok := h.Dispatcher.(CommandValidator)
+	if !ok {
+		return fmt.Errorf("%s execution policy requires a dispatcher that implements CommandValidator", AllOrNothing)
+	}
+
+	for i := range results {
+		result := &results[i]
+
+		if result.Status == ResultInvalid {
+			continue
+		}
+
+		ctx, decision := command.WithDecisionRecorder(ctx)
+		ctx, name := command.WithCommandNameRecorder(ctx)
+
+		err := validator.Validate(ctx, event, result.Args)
+		result.Decision = decision()
+		result.setName(name())
 
-		err = h.Dispatcher.Dispatch(ctx, event, args)
 		if err != nil {
-			return err
+			result.Status = failureStatus(err, ResultInvalid)
+			result.Err = err
 		}
 	}
 
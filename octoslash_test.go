@@ -0,0 +1,411 @@
+package octoslash_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/parser"
+	"github.com/sagikazarmark/octoslash/retry"
+)
+
+func TestEventHandler_ExecutionPolicy(t *testing.T) {
+	const body = "/label a\n/fail\n/label 'b\n/label c"
+
+	testCases := []struct {
+		policy     octoslash.ExecutionPolicy
+		dispatched []string
+		statuses   []octoslash.ResultStatus
+	}{
+		{
+			policy:     octoslash.StopOnError,
+			dispatched: []string{"label", "fail"},
+			statuses: []octoslash.ResultStatus{
+				octoslash.ResultSucceeded,
+				octoslash.ResultFailed,
+				octoslash.ResultInvalid,
+				octoslash.ResultSkipped,
+			},
+		},
+		{
+			policy:     octoslash.ContinueOnError,
+			dispatched: []string{"label", "fail", "label"},
+			statuses: []octoslash.ResultStatus{
+				octoslash.ResultSucceeded,
+				octoslash.ResultFailed,
+				octoslash.ResultInvalid,
+				octoslash.ResultSucceeded,
+			},
+		},
+		{
+			policy: octoslash.AllOrNothing,
+			statuses: []octoslash.ResultStatus{
+				octoslash.ResultSkipped,
+				octoslash.ResultSkipped,
+				octoslash.ResultInvalid,
+				octoslash.ResultSkipped,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.policy.String(), func(t *testing.T) {
+			var dispatched []string
+
+			handler := octoslash.EventHandler{
+				Dispatcher: octoslash.CommandDispatcherFunc(func(
+					_ context.Context,
+					_ github.IssueCommentEvent,
+					args []string,
+				) error {
+					dispatched = append(dispatched, args[0])
+
+					if args[0] == "fail" {
+						return errors.New("failed")
+					}
+
+					return nil
+				}),
+				ExecutionPolicy: testCase.policy,
+			}
+
+			event := github.IssueCommentEvent{
+				Comment: &github.IssueComment{Body: github.Ptr(body)},
+			}
+
+			results, err := handler.Execute(t.Context(), event)
+			if err == nil {
+				t.Error("expected an error")
+			}
+
+			if !slices.Equal(dispatched, testCase.dispatched) {
+				t.Errorf("expected dispatched commands %v, got %v", testCase.dispatched, dispatched)
+			}
+
+			statuses := make([]octoslash.ResultStatus, 0, len(results))
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+
+			if !slices.Equal(statuses, testCase.statuses) {
+				t.Errorf("expected statuses %v, got %v", testCase.statuses, statuses)
+			}
+		})
+	}
+}
+
+func TestEventHandler_ParseError(t *testing.T) {
+	const body = "/label 'a\n/label b"
+
+	testCases := []struct {
+		policy     octoslash.ExecutionPolicy
+		dispatched []string
+		statuses   []octoslash.ResultStatus
+		wantErr    bool
+	}{
+		{
+			policy:     octoslash.StopOnError,
+			dispatched: []string{"label"},
+			statuses:   []octoslash.ResultStatus{octoslash.ResultInvalid, octoslash.ResultSucceeded},
+			wantErr:    true,
+		},
+		{
+			policy:     octoslash.ContinueOnError,
+			dispatched: []string{"label"},
+			statuses:   []octoslash.ResultStatus{octoslash.ResultInvalid, octoslash.ResultSucceeded},
+			wantErr:    true,
+		},
+		{
+			policy:   octoslash.AllOrNothing,
+			statuses: []octoslash.ResultStatus{octoslash.ResultInvalid, octoslash.ResultSkipped},
+			wantErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.policy.String(), func(t *testing.T) {
+			var dispatched []string
+
+			handler := octoslash.EventHandler{
+				Dispatcher: octoslash.CommandDispatcherFunc(func(
+					_ context.Context,
+					_ github.IssueCommentEvent,
+					args []string,
+				) error {
+					dispatched = append(dispatched, args[0])
+
+					return nil
+				}),
+				ExecutionPolicy: testCase.policy,
+			}
+
+			event := github.IssueCommentEvent{
+				Comment: &github.IssueComment{Body: github.Ptr(body)},
+			}
+
+			results, err := handler.Execute(t.Context(), event)
+			if testCase.wantErr != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(dispatched, testCase.dispatched) {
+				t.Errorf("expected dispatched commands %v, got %v", testCase.dispatched, dispatched)
+			}
+
+			statuses := make([]octoslash.ResultStatus, 0, len(results))
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+
+			if !slices.Equal(statuses, testCase.statuses) {
+				t.Errorf("expected statuses %v, got %v", testCase.statuses, statuses)
+			}
+		})
+	}
+}
+
+type validatingDispatcher struct {
+	octoslash.CommandDispatcherFunc
+}
+
+func (validatingDispatcher) Validate(
+	_ context.Context,
+	_ github.IssueCommentEvent,
+	args []string,
+) error {
+	if args[0] == "unknown" {
+		return errors.New("unknown command")
+	}
+
+	return nil
+}
+
+func TestEventHandler_AllOrNothing_Validate(t *testing.T) {
+	var dispatched []string
+
+	dispatcher := validatingDispatcher{
+		CommandDispatcherFunc: func(_ context.Context, _ github.IssueCommentEvent, args []string) error {
+			dispatched = append(dispatched, args[0])
+
+			return nil
+		},
+	}
+
+	handler := octoslash.EventHandler{
+		// Validation should work through middleware
+		Dispatcher:      octoslash.Chain(dispatcher, octoslash.RecoverMiddleware()),
+		ExecutionPolicy: octoslash.AllOrNothing,
+	}
+
+	event := github.IssueCommentEvent{
+		Comment: &github.IssueComment{Body: github.Ptr("/label a\n/unknown\n/close")},
+	}
+
+	results, err := handler.Execute(t.Context(), event)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	if len(dispatched) > 0 {
+		t.Errorf("expected no commands to be dispatched, got %v", dispatched)
+	}
+
+	if results[1].Status != octoslash.ResultInvalid {
+		t.Errorf("expected the unknown command to be invalid, got %s", results[1].Status)
+	}
+}
+
+func TestEventHandler_AllOrNothing_NoValidator(t *testing.T) {
+	var dispatched []string
+
+	handler := octoslash.EventHandler{
+		Dispatcher: octoslash.CommandDispatcherFunc(func(_ context.Context, _ github.IssueCommentEvent, args []string) error {
+			dispatched = append(dispatched, args[0])
+
+			return nil
+		}),
+		ExecutionPolicy: octoslash.AllOrNothing,
+	}
+
+	event := github.IssueCommentEvent{
+		Comment: &github.IssueComment{Body: github.Ptr("/label a\n/close")},
+	}
+
+	results, err := handler.Execute(t.Context(), event)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	if len(dispatched) > 0 {
+		t.Errorf("expected no commands to be dispatched, got %v", dispatched)
+	}
+
+	for _, result := range results {
+		if result.Status != octoslash.ResultSkipped {
+			t.Errorf("expected %s to be skipped, got %s", result.Command, result.Status)
+		}
+	}
+}
+
+type rejectingDispatcher struct {
+	octoslash.CommandDispatcherFunc
+
+	rejected *[]string
+}
+
+func (d rejectingDispatcher) Reject(_ context.Context, _ github.IssueCommentEvent, command string, _ error) {
+	*d.rejected = append(*d.rejected, command)
+}
+
+func TestEventHandler_Reject(t *testing.T) {
+	var rejected []string
+
+	dispatcher := rejectingDispatcher{
+		CommandDispatcherFunc: func(context.Context, github.IssueCommentEvent, []string) error {
+			return nil
+		},
+		rejected: &rejected,
+	}
+
+	handler := octoslash.EventHandler{
+		// Rejection should work through middleware
+		Dispatcher: octoslash.Chain(dispatcher, octoslash.RecoverMiddleware()),
+	}
+
+	event := github.IssueCommentEvent{
+		Comment: &github.IssueComment{Body: github.Ptr("/label 'a\n/close")},
+	}
+
+	// Rejected commands fail the comment
+	_, err := handler.Execute(t.Context(), event)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if expected := []string{"label 'a"}; !slices.Equal(rejected, expected) {
+		t.Errorf("expected rejected commands %v, got %v", expected, rejected)
+	}
+}
+
+func TestEventHandler_Decision(t *testing.T) {
+	handler := octoslash.EventHandler{
+		Dispatcher: octoslash.CommandDispatcherFunc(func(
+			ctx context.Context,
+			_ github.IssueCommentEvent,
+			args []string,
+		) error {
+			// Decisions are recorded by nested recorders as well (eg. audit logging)
+			ctx, _ = command.WithDecisionRecorder(ctx)
+
+			if args[0] == "close" {
+				command.RecordDecision(ctx, command.Decision{Policies: []string{"policy0"}})
+
+				return command.ErrUnauthorized
+			}
+
+			return nil
+		}),
+		ExecutionPolicy: octoslash.ContinueOnError,
+	}
+
+	event := github.IssueCommentEvent{
+		Comment: &github.IssueComment{Body: github.Ptr("/label a\n/close")},
+	}
+
+	results, _ := handler.Execute(t.Context(), event)
+
+	if results[0].Decision != nil {
+		t.Errorf("expected no decision, got %v", results[0].Decision)
+	}
+
+	if decision := results[1].Decision; decision == nil || decision.Allowed ||
+		!slices.Equal(decision.Policies, []string{"policy0"}) {
+		t.Errorf("expected a recorded deny decision, got %v", decision)
+	}
+}
+
+func TestEventHandler_RateLimit(t *testing.T) {
+	handler := octoslash.EventHandler{
+		Dispatcher: octoslash.CommandDispatcherFunc(func(
+			_ context.Context,
+			_ github.IssueCommentEvent,
+			_ []string,
+		) error {
+			// HTTP clients wrap transport errors
+			return &url.Error{
+				Op:  "Get",
+				URL: "https://api.github.com/repos/owner/repo/labels",
+				Err: &retry.RateLimitError{Reset: time.Now().Add(time.Hour)},
+			}
+		}),
+	}
+
+	event := github.IssueCommentEvent{
+		Comment: &github.IssueComment{Body: github.Ptr("/label a\n/close")},
+	}
+
+	results, err := handler.Execute(t.Context(), event)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	if results[0].Status != octoslash.ResultRateLimited {
+		t.Errorf("expected the command to be rate limited, got %s", results[0].Status)
+	}
+
+	if results[1].Status != octoslash.ResultSkipped {
+		t.Errorf("expected the next command to be skipped, got %s", results[1].Status)
+	}
+}
+
+func TestEventHandler_LoadTrigger(t *testing.T) {
+	var loads int
+
+	handler := octoslash.EventHandler{
+		Dispatcher: octoslash.CommandDispatcherFunc(func(context.Context, github.IssueCommentEvent, []string) error {
+			return nil
+		}),
+		LoadTrigger: func() (parser.Trigger, error) {
+			loads++
+
+			return parser.Trigger{Style: parser.TriggerMention, Name: "octoslash"}, nil
+		},
+	}
+
+	execute := func(body string) octoslash.Results {
+		t.Helper()
+
+		results, err := handler.Execute(t.Context(), github.IssueCommentEvent{
+			Comment: &github.IssueComment{Body: github.Ptr(body)},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return results
+	}
+
+	// The trigger is not loaded for comments that cannot contain commands
+	execute("LGTM")
+
+	if loads != 0 {
+		t.Errorf("expected the trigger not to be loaded, got %d loads", loads)
+	}
+
+	results := execute("/label a\n@octoslash close")
+
+	if loads != 1 {
+		t.Errorf("expected the trigger to be loaded once, got %d loads", loads)
+	}
+
+	if len(results) != 1 || results[0].Command != "close" {
+		t.Errorf("expected the loaded trigger to be used, got %v", results)
+	}
+}
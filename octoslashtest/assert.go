@@ -0,0 +1,125 @@
+package octoslashtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sagikazarmark/octoslash"
+)
+
+// AssertRequests checks that the server received exactly the given mutating requests (in order).
+//
+// Requests are formatted as "METHOD path" (see [Request.String]).
+func AssertRequests(tb testing.TB, server *Server, want ...string) {
+	tb.Helper()
+
+	var got []string
+	for _, r := range server.MutatingRequests() {
+		got = append(got, r.String())
+	}
+
+	if !slices.Equal(got, want) {
+		tb.Errorf("unexpected requests\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+// AssertNoRequests checks that the server received no mutating requests.
+func AssertNoRequests(tb testing.TB, server *Server) {
+	tb.Helper()
+
+	AssertRequests(tb, server)
+}
+
+// AssertLabels checks the labels of an issue (regardless of their order).
+func AssertLabels(tb testing.TB, issue *Issue, want ...string) {
+	tb.Helper()
+
+	if !equalSet(issue.Labels, want) {
+		tb.Errorf("unexpected labels on #%d\nwant: %q\ngot:  %q", issue.Number, want, issue.Labels)
+	}
+}
+
+// AssertAssignees checks the assignees of an issue (regardless of their order).
+func AssertAssignees(tb testing.TB, issue *Issue, want ...string) {
+	tb.Helper()
+
+	if !equalSet(issue.Assignees, want) {
+		tb.Errorf(
+			"unexpected assignees on #%d\nwant: %q\ngot:  %q",
+			issue.Number,
+			want,
+			issue.Assignees,
+		)
+	}
+}
+
+// AssertState checks the state (and the state reason if not empty) of an issue.
+func AssertState(tb testing.TB, issue *Issue, state string, reason string) {
+	tb.Helper()
+
+	if issue.State != state {
+		tb.Errorf("unexpected state of #%d\nwant: %q\ngot:  %q", issue.Number, state, issue.State)
+	}
+
+	if reason != "" && issue.StateReason != reason {
+		tb.Errorf(
+			"unexpected state reason of #%d\nwant: %q\ngot:  %q",
+			issue.Number,
+			reason,
+			issue.StateReason,
+		)
+	}
+}
+
+// AssertComment checks that a comment containing the given text was created on an issue.
+func AssertComment(tb testing.TB, issue *Issue, contains string) {
+	tb.Helper()
+
+	for _, comment := range issue.Comments {
+		if strings.Contains(comment, contains) {
+			return
+		}
+	}
+
+	tb.Errorf("no comment on #%d contains %q\ncomments: %q", issue.Number, contains, issue.Comments)
+}
+
+// AssertDispatched checks that a workflow was dispatched on a ref.
+func AssertDispatched(tb testing.TB, repo *Repository, workflow string, ref string) {
+	tb.Helper()
+
+	for _, dispatch := range repo.Dispatches {
+		if dispatch.Workflow == workflow && dispatch.Ref == ref {
+			return
+		}
+	}
+
+	tb.Errorf(
+		"workflow %s was not dispatched on %s\ndispatches: %+v",
+		workflow,
+		ref,
+		repo.Dispatches,
+	)
+}
+
+// AssertResults checks the status of every command handled by [octoslash.EventHandler].
+func AssertResults(tb testing.TB, results octoslash.Results, want ...octoslash.ResultStatus) {
+	tb.Helper()
+
+	var got []octoslash.ResultStatus
+	for _, result := range results {
+		got = append(got, result.Status)
+	}
+
+	if !slices.Equal(got, want) {
+		tb.Errorf("unexpected results\nwant: %v\ngot:  %v\nerror: %v", want, got, results.Err())
+	}
+}
+
+func equalSet(a []string, b []string) bool {
+	a = slices.Sorted(slices.Values(a))
+	b = slices.Sorted(slices.Values(b))
+
+	return slices.Equal(a, b)
+}
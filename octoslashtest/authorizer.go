@@ -0,0 +1,42 @@
+package octoslashtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+// Authorizer is a [command.Authorizer] allowing every action, except the denied ones.
+type Authorizer struct {
+	// Denied actions.
+	Denied []string
+}
+
+// AllowAll returns an [Authorizer] allowing every action.
+func AllowAll() Authorizer {
+	return Authorizer{}
+}
+
+// Deny returns an [Authorizer] denying the given actions (and allowing everything else).
+func Deny(actions ...string) Authorizer {
+	return Authorizer{
+		Denied: actions,
+	}
+}
+
+// Authorize implements [command.Authorizer].
+func (a Authorizer) Authorize(ctx context.Context, _ github.IssueCommentEvent, action string) error {
+	allowed := !slices.Contains(a.Denied, action)
+
+	command.RecordDecision(ctx, command.Decision{Allowed: allowed})
+
+	if !allowed {
+		return fmt.Errorf("%w: action %s is denied", command.ErrUnauthorized, action)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package octoslashtest
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// EventOption configures an event created by [NewIssueCommentEvent].
+type EventOption func(event *github.IssueCommentEvent)
+
+// NewIssueCommentEvent creates an event of a new comment with the given body.
+//
+// By default, the comment is created on issue #1 of owner/repo by octocat (ID 1), who is also the author of the issue.
+func NewIssueCommentEvent(body string, opts ...EventOption) github.IssueCommentEvent {
+	user := &github.User{
+		Login: github.Ptr("octocat"),
+		ID:    github.Ptr(int64(1)),
+	}
+
+	event := github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Repo: &github.Repository{
+			ID:            github.Ptr(int64(1)),
+			Owner:         &github.User{Login: github.Ptr("owner")},
+			Name:          github.Ptr("repo"),
+			FullName:      github.Ptr("owner/repo"),
+			DefaultBranch: github.Ptr("main"),
+		},
+		Issue: &github.Issue{
+			ID:     github.Ptr(int64(1)),
+			Number: github.Ptr(1),
+			State:  github.Ptr("open"),
+			User:   user,
+		},
+		Comment: &github.IssueComment{
+			ID:   github.Ptr(int64(1)),
+			Body: github.Ptr(body),
+			User: user,
+		},
+		Sender: user,
+	}
+
+	for _, opt := range opts {
+		opt(&event)
+	}
+
+	return event
+}
+
+// WithRepository sets the repository of the event.
+func WithRepository(owner string, name string) EventOption {
+	return func(event *github.IssueCommentEvent) {
+		event.Repo.Owner = &github.User{Login: github.Ptr(owner)}
+		event.Repo.Name = github.Ptr(name)
+		event.Repo.FullName = github.Ptr(owner + "/" + name)
+	}
+}
+
+// WithIssue sets the issue the comment is created on.
+//
+// It copies the title, state, author, lock, labels, assignees and pull request status of the issue
+// (so that the event matches an issue of a [Repository] served by [Server]).
+func WithIssue(issue *Issue) EventOption {
+	return func(event *github.IssueCommentEvent) {
+		i := event.Issue
+
+		i.ID = github.Ptr(int64(issue.Number))
+		i.Number = github.Ptr(issue.Number)
+		i.Title = github.Ptr(issue.Title)
+		i.Locked = github.Ptr(issue.Locked)
+
+		if issue.State != "" {
+			i.State = github.Ptr(issue.State)
+		}
+
+		if issue.Author != "" {
+			i.User = &github.User{Login: github.Ptr(issue.Author)}
+		}
+
+		i.Labels = nil
+		for _, label := range issue.Labels {
+			i.Labels = append(i.Labels, &github.Label{Name: github.Ptr(label)})
+		}
+
+		i.Assignees = nil
+		for _, assignee := range issue.Assignees {
+			i.Assignees = append(i.Assignees, &github.User{Login: github.Ptr(assignee)})
+		}
+
+		i.PullRequestLinks = nil
+		if issue.PullRequest {
+			WithPullRequest()(event)
+		}
+	}
+}
+
+// WithIssueNumber sets the number of the issue the comment is created on.
+func WithIssueNumber(number int) EventOption {
+	return func(event *github.IssueCommentEvent) {
+		event.Issue.ID = github.Ptr(int64(number))
+		event.Issue.Number = github.Ptr(number)
+	}
+}
+
+// WithPullRequest marks the issue the comment is created on as a pull request.
+func WithPullRequest() EventOption {
+	return func(event *github.IssueCommentEvent) {
+		event.Issue.PullRequestLinks = &github.PullRequestLinks{
+			URL: github.Ptr(fmt.Sprintf(
+				"https://api.github.com/repos/%s/pulls/%d",
+				event.Repo.GetFullName(),
+				event.Issue.GetNumber(),
+			)),
+		}
+	}
+}
+
+// WithUser sets the user creating the comment.
+func WithUser(login string, id int64) EventOption {
+	return func(event *github.IssueCommentEvent) {
+		user := &github.User{
+			Login: github.Ptr(login),
+			ID:    github.Ptr(id),
+		}
+
+		event.Comment.User = user
+		event.Sender = user
+	}
+}
+
+// WithAuthor sets the author of the issue the comment is created on.
+func WithAuthor(login string, id int64) EventOption {
+	return func(event *github.IssueCommentEvent) {
+		event.Issue.User = &github.User{
+			Login: github.Ptr(login),
+			ID:    github.Ptr(id),
+		}
+	}
+}
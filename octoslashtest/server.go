@@ -0,0 +1,922 @@
+// Package octoslashtest provides utilities for testing commands and event handling without GitHub.
+package octoslashtest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Server is an in-memory fake of the GitHub API.
+//
+// It implements the subset of the API used by octoslash (issues, labels, milestones, assignees, pull requests,
+// workflow dispatches and repository contents) on top of repositories registered with [Server.AddRepository].
+//
+// Every request is recorded and can be inspected using [Server.Requests].
+type Server struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	repos    map[string]*Repository
+	requests []Request
+	nextID   int64
+}
+
+// Request is an API request received by [Server].
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// String returns the method and the path of the request (eg. "POST /repos/owner/repo/issues/1/labels").
+func (r Request) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Repository is a repository served by [Server].
+//
+// Fields can be modified between (but not during) requests, preferably before running commands.
+type Repository struct {
+	Owner string
+	Name  string
+
+	// DefaultBranch defaults to main.
+	DefaultBranch string
+
+	// Labels available in the repository.
+	Labels []string
+
+	Milestones []Milestone
+
+	Issues map[int]*Issue
+
+	// Files on the default branch (by path).
+	Files map[string]string
+
+	// Dispatches are the workflow dispatch events received.
+	Dispatches []WorkflowDispatch
+
+	// WorkflowConclusion is the conclusion of workflow runs created by dispatches (defaults to success).
+	WorkflowConclusion string
+
+	// WorkflowStatus is the status of workflow runs created by dispatches (defaults to completed).
+	WorkflowStatus string
+
+	// ConcurrentDispatches is the number of runs dispatched by someone else along with every dispatch
+	// (with the same workflow and ref, but without inputs).
+	ConcurrentDispatches int
+
+	runs []*github.WorkflowRun
+}
+
+// FullName returns the full name of the repository (owner/name).
+func (r *Repository) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// Milestone is a milestone of a [Repository].
+type Milestone struct {
+	Number int
+	Title  string
+}
+
+// Issue is an issue (or pull request) of a [Repository].
+type Issue struct {
+	Number int
+	Title  string
+
+	// State is open or closed (defaults to open).
+	State       string
+	StateReason string
+
+	// Author is the login of the user who opened the issue.
+	Author string
+
+	Locked     bool
+	LockReason string
+
+	Labels    []string
+	Assignees []string
+
+	// Milestone is the number of the milestone of the issue (0 if none).
+	Milestone int
+
+	// Comments are the bodies of comments created through the API.
+	Comments []string
+
+	// PullRequest marks the issue as a pull request.
+	PullRequest bool
+
+	// HeadRef is the head branch of a pull request.
+	HeadRef string
+}
+
+// WorkflowDispatch is a workflow dispatch event received by [Server].
+type WorkflowDispatch struct {
+	Workflow string
+	Ref      string
+	Inputs   map[string]any
+}
+
+// NewServer starts a new [Server].
+//
+// The server is closed when the test finishes.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	s := &Server{
+		repos: make(map[string]*Repository),
+	}
+
+	s.server = httptest.NewServer(s.handler())
+	tb.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.server.URL + "/"
+}
+
+// Client returns a GitHub client sending requests to the server.
+func (s *Server) Client() *github.Client {
+	client := github.NewClient(s.server.Client())
+
+	baseURL, _ := url.Parse(s.URL())
+	client.BaseURL = baseURL
+	client.UploadURL = baseURL
+
+	return client
+}
+
+// AddRepository registers a repository and returns it.
+func (s *Server) AddRepository(repo *Repository) *Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if repo.DefaultBranch == "" {
+		repo.DefaultBranch = "main"
+	}
+
+	if repo.Issues == nil {
+		repo.Issues = make(map[int]*Issue)
+	}
+
+	for _, issue := range repo.Issues {
+		if issue.State == "" {
+			issue.State = "open"
+		}
+	}
+
+	s.repos[repo.FullName()] = repo
+
+	return repo
+}
+
+// Repository returns a registered repository (or nil if it does not exist).
+func (s *Server) Repository(owner string, name string) *Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.repos[owner+"/"+name]
+}
+
+// Requests returns the requests received by the server.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// MutatingRequests returns the requests received by the server that would change something on GitHub.
+func (s *Server) MutatingRequests() []Request {
+	var requests []Request
+
+	for _, r := range s.Requests() {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
+
+type apiHandler func(w http.ResponseWriter, r *http.Request, repo *Repository)
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	handle := func(pattern string, handler apiHandler) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			repo := s.repos[r.PathValue("owner")+"/"+r.PathValue("repo")]
+			if repo == nil {
+				writeError(w, http.StatusNotFound, "Not Found")
+
+				return
+			}
+
+			handler(w, r, repo)
+		})
+	}
+
+	handle("GET /repos/{owner}/{repo}", s.getRepository)
+	handle("GET /repos/{owner}/{repo}/git/ref/heads/{branch...}", s.getBranchRef)
+	handle("GET /repos/{owner}/{repo}/git/trees/{sha}", s.getTree)
+	handle("GET /repos/{owner}/{repo}/git/blobs/{sha}", s.getBlob)
+
+	handle("GET /repos/{owner}/{repo}/labels", s.listLabels)
+	handle("GET /repos/{owner}/{repo}/milestones", s.listMilestones)
+
+	handle("GET /repos/{owner}/{repo}/issues/{number}", s.issueHandler(s.getIssue))
+	handle("PATCH /repos/{owner}/{repo}/issues/{number}", s.issueHandler(s.editIssue))
+	handle("GET /repos/{owner}/{repo}/issues/{number}/labels", s.issueHandler(s.listIssueLabels))
+	handle("POST /repos/{owner}/{repo}/issues/{number}/labels", s.issueHandler(s.addLabels))
+	handle(
+		"DELETE /repos/{owner}/{repo}/issues/{number}/labels/{name...}",
+		s.issueHandler(s.removeLabel),
+	)
+	handle("POST /repos/{owner}/{repo}/issues/{number}/assignees", s.issueHandler(s.addAssignees))
+	handle(
+		"DELETE /repos/{owner}/{repo}/issues/{number}/assignees",
+		s.issueHandler(s.removeAssignees),
+	)
+	handle("PUT /repos/{owner}/{repo}/issues/{number}/lock", s.issueHandler(s.lockIssue))
+	handle("DELETE /repos/{owner}/{repo}/issues/{number}/lock", s.issueHandler(s.unlockIssue))
+	handle("POST /repos/{owner}/{repo}/issues/{number}/comments", s.issueHandler(s.createComment))
+	handle("GET /repos/{owner}/{repo}/pulls/{number}", s.issueHandler(s.getPullRequest))
+
+	handle("POST /repos/{owner}/{repo}/actions/workflows/{workflow}/dispatches", s.dispatchWorkflow)
+	handle("GET /repos/{owner}/{repo}/actions/workflows/{workflow}/runs", s.listWorkflowRuns)
+	handle("GET /repos/{owner}/{repo}/actions/runs/{id}", s.getWorkflowRun)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Body:   string(bytes.TrimSpace(body)),
+		})
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) issueHandler(
+	handler func(w http.ResponseWriter, r *http.Request, repo *Repository, issue *Issue),
+) apiHandler {
+	return func(w http.ResponseWriter, r *http.Request, repo *Repository) {
+		number, err := strconv.Atoi(r.PathValue("number"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+
+			return
+		}
+
+		issue := repo.Issues[number]
+		if issue == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+
+			return
+		}
+
+		handler(w, r, repo, issue)
+	}
+}
+
+func (s *Server) getRepository(w http.ResponseWriter, _ *http.Request, repo *Repository) {
+	writeJSON(w, http.StatusOK, newRepository(repo))
+}
+
+func (s *Server) getBranchRef(w http.ResponseWriter, r *http.Request, repo *Repository) {
+	if r.PathValue("branch") != repo.DefaultBranch {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + repo.DefaultBranch),
+		Object: &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr(repo.commitSHA())},
+	})
+}
+
+// getTree returns the (always recursive) tree of the current commit.
+func (s *Server) getTree(w http.ResponseWriter, r *http.Request, repo *Repository) {
+	sha := r.PathValue("sha")
+
+	dirs := repo.dirs()
+
+	// The tree of a commit can be looked up by the commit (or the branch) as well
+	dir := "."
+	if sha != repo.DefaultBranch && sha != repo.commitSHA() {
+		i := slices.IndexFunc(dirs, func(dir string) bool { return repo.treeSHA(dir) == sha })
+		if i < 0 {
+			writeError(w, http.StatusNotFound, "Not Found")
+
+			return
+		}
+
+		dir = dirs[i]
+	}
+
+	recursive := r.URL.Query().Get("recursive") != ""
+
+	// included reports whether a path of the repository is listed in the tree (and returns its path relative to the tree)
+	included := func(name string) (string, bool) {
+		rel := name
+		if dir != "." {
+			var ok bool
+
+			rel, ok = strings.CutPrefix(name, dir+"/")
+			if !ok {
+				return "", false
+			}
+		}
+
+		return rel, recursive || !strings.Contains(rel, "/")
+	}
+
+	var entries []*github.TreeEntry
+
+	for _, name := range slices.Sorted(maps.Keys(repo.Files)) {
+		rel, ok := included(name)
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, &github.TreeEntry{
+			Path: github.Ptr(rel),
+			Mode: github.Ptr("100644"),
+			Type: github.Ptr("blob"),
+			SHA:  github.Ptr(blobSHA(repo.Files[name])),
+			Size: github.Ptr(len(repo.Files[name])),
+		})
+	}
+
+	for _, name := range dirs {
+		rel, ok := included(name)
+		if !ok || name == "." {
+			continue
+		}
+
+		entries = append(entries, &github.TreeEntry{
+			Path: github.Ptr(rel),
+			Mode: github.Ptr("040000"),
+			Type: github.Ptr("tree"),
+			SHA:  github.Ptr(repo.treeSHA(name)),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, &github.Tree{
+		SHA:     github.Ptr(repo.treeSHA(dir)),
+		Entries: entries,
+	})
+}
+
+func (s *Server) getBlob(w http.ResponseWriter, r *http.Request, repo *Repository) {
+	for _, content := range repo.Files {
+		if blobSHA(content) != r.PathValue("sha") {
+			continue
+		}
+
+		if strings.Contains(r.Header.Get("Accept"), "raw") {
+			_, _ = io.WriteString(w, content)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, &github.Blob{
+			SHA:      github.Ptr(blobSHA(content)),
+			Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+			Encoding: github.Ptr("base64"),
+			Size:     github.Ptr(len(content)),
+		})
+
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func (s *Server) listLabels(w http.ResponseWriter, _ *http.Request, repo *Repository) {
+	labels := []*github.Label{}
+	for _, name := range repo.Labels {
+		labels = append(labels, &github.Label{Name: github.Ptr(name)})
+	}
+
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func (s *Server) listMilestones(w http.ResponseWriter, _ *http.Request, repo *Repository) {
+	milestones := []*github.Milestone{}
+	for _, milestone := range repo.Milestones {
+		milestones = append(milestones, newMilestone(milestone))
+	}
+
+	writeJSON(w, http.StatusOK, milestones)
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, _ *http.Request, repo *Repository, issue *Issue) {
+	writeJSON(w, http.StatusOK, newIssue(repo, issue))
+}
+
+func (s *Server) editIssue(w http.ResponseWriter, r *http.Request, repo *Repository, issue *Issue) {
+	var req map[string]json.RawMessage
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	decode := func(key string, v any) bool {
+		raw, ok := req[key]
+		if !ok {
+			return false
+		}
+
+		return json.Unmarshal(raw, v) == nil
+	}
+
+	_ = decode("title", &issue.Title)
+	_ = decode("state", &issue.State)
+	_ = decode("state_reason", &issue.StateReason)
+	_ = decode("labels", &issue.Labels)
+	_ = decode("assignees", &issue.Assignees)
+
+	if raw, ok := req["milestone"]; ok {
+		var milestone *int
+		if err := json.Unmarshal(raw, &milestone); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		if milestone == nil {
+			issue.Milestone = 0
+		} else if !slices.ContainsFunc(repo.Milestones, func(m Milestone) bool { return m.Number == *milestone }) {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+
+			return
+		} else {
+			issue.Milestone = *milestone
+		}
+	}
+
+	writeJSON(w, http.StatusOK, newIssue(repo, issue))
+}
+
+func (s *Server) listIssueLabels(
+	w http.ResponseWriter,
+	_ *http.Request,
+	repo *Repository,
+	issue *Issue,
+) {
+	writeJSON(w, http.StatusOK, newIssue(repo, issue).Labels)
+}
+
+func (s *Server) addLabels(w http.ResponseWriter, r *http.Request, repo *Repository, issue *Issue) {
+	var labels []string
+
+	if err := json.NewDecoder(r.Body).Decode(&labels); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	for i, label := range labels {
+		// GitHub matches existing labels case-insensitively and creates missing ones
+		if j := slices.IndexFunc(repo.Labels, func(l string) bool {
+			return strings.EqualFold(l, label)
+		}); j >= 0 {
+			label = repo.Labels[j]
+			labels[i] = label
+		} else {
+			repo.Labels = append(repo.Labels, label)
+		}
+
+		if !slices.Contains(issue.Labels, label) {
+			issue.Labels = append(issue.Labels, label)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, newIssue(repo, issue).Labels)
+}
+
+func (s *Server) removeLabel(
+	w http.ResponseWriter,
+	r *http.Request,
+	repo *Repository,
+	issue *Issue,
+) {
+	name := r.PathValue("name")
+
+	i := slices.Index(issue.Labels, name)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Label does not exist")
+
+		return
+	}
+
+	issue.Labels = slices.Delete(issue.Labels, i, i+1)
+
+	writeJSON(w, http.StatusOK, newIssue(repo, issue).Labels)
+}
+
+type assigneesRequest struct {
+	Assignees []string `json:"assignees"`
+}
+
+func (s *Server) addAssignees(
+	w http.ResponseWriter,
+	r *http.Request,
+	repo *Repository,
+	issue *Issue,
+) {
+	var req assigneesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	for _, assignee := range req.Assignees {
+		if !slices.Contains(issue.Assignees, assignee) {
+			issue.Assignees = append(issue.Assignees, assignee)
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, newIssue(repo, issue))
+}
+
+func (s *Server) removeAssignees(
+	w http.ResponseWriter,
+	r *http.Request,
+	repo *Repository,
+	issue *Issue,
+) {
+	var req assigneesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	issue.Assignees = slices.DeleteFunc(issue.Assignees, func(assignee string) bool {
+		return slices.Contains(req.Assignees, assignee)
+	})
+
+	writeJSON(w, http.StatusOK, newIssue(repo, issue))
+}
+
+func (s *Server) lockIssue(w http.ResponseWriter, r *http.Request, _ *Repository, issue *Issue) {
+	var req struct {
+		LockReason string `json:"lock_reason"`
+	}
+
+	// The body is optional
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	issue.Locked = true
+	issue.LockReason = req.LockReason
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) unlockIssue(w http.ResponseWriter, _ *http.Request, _ *Repository, issue *Issue) {
+	issue.Locked = false
+	issue.LockReason = ""
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createComment(
+	w http.ResponseWriter,
+	r *http.Request,
+	_ *Repository,
+	issue *Issue,
+) {
+	var req struct {
+		Body string `json:"body"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	issue.Comments = append(issue.Comments, req.Body)
+
+	s.nextID++
+
+	writeJSON(w, http.StatusCreated, &github.IssueComment{
+		ID:   github.Ptr(s.nextID),
+		Body: github.Ptr(req.Body),
+	})
+}
+
+func (s *Server) getPullRequest(
+	w http.ResponseWriter,
+	_ *http.Request,
+	repo *Repository,
+	issue *Issue,
+) {
+	if !issue.PullRequest {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &github.PullRequest{
+		Number: github.Ptr(issue.Number),
+		Title:  github.Ptr(issue.Title),
+		State:  github.Ptr(issue.State),
+		Head: &github.PullRequestBranch{
+			Ref:  github.Ptr(issue.HeadRef),
+			Repo: newRepository(repo),
+		},
+		Base: &github.PullRequestBranch{
+			Ref:  github.Ptr(repo.DefaultBranch),
+			Repo: newRepository(repo),
+		},
+	})
+}
+
+func (s *Server) dispatchWorkflow(w http.ResponseWriter, r *http.Request, repo *Repository) {
+	var req struct {
+		Ref    string         `json:"ref"`
+		Inputs map[string]any `json:"inputs"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	workflow := r.PathValue("workflow")
+
+	repo.Dispatches = append(repo.Dispatches, WorkflowDispatch{
+		Workflow: workflow,
+		Ref:      req.Ref,
+		Inputs:   req.Inputs,
+	})
+
+	for range repo.ConcurrentDispatches {
+		s.addWorkflowRun(repo, workflow, req.Ref, nil)
+	}
+
+	s.addWorkflowRun(repo, workflow, req.Ref, req.Inputs)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addWorkflowRun creates a run for a dispatch.
+//
+// The run is titled after the workflow and the input values (as if its run-name included every input).
+func (s *Server) addWorkflowRun(
+	repo *Repository,
+	workflow string,
+	ref string,
+	inputs map[string]any,
+) {
+	status := repo.WorkflowStatus
+	if status == "" {
+		status = "completed"
+	}
+
+	var conclusion *string
+	if status == "completed" {
+		conclusion = github.Ptr(repo.WorkflowConclusion)
+		if *conclusion == "" {
+			conclusion = github.Ptr("success")
+		}
+	}
+
+	title := workflow
+	for _, key := range slices.Sorted(maps.Keys(inputs)) {
+		title += fmt.Sprintf(" %v", inputs[key])
+	}
+
+	s.nextID++
+
+	repo.runs = append(repo.runs, &github.WorkflowRun{
+		ID:           github.Ptr(s.nextID),
+		Name:         github.Ptr(workflow),
+		DisplayTitle: github.Ptr(title),
+		RunNumber:    github.Ptr(len(repo.runs) + 1),
+		Path:         github.Ptr(".github/workflows/" + workflow),
+		HeadBranch:   github.Ptr(ref),
+		Event:        github.Ptr("workflow_dispatch"),
+		Status:       github.Ptr(status),
+		Conclusion:   conclusion,
+		CreatedAt:    &github.Timestamp{Time: time.Now()},
+		HTMLURL: github.Ptr(
+			fmt.Sprintf("https://github.com/%s/actions/runs/%d", repo.FullName(), s.nextID),
+		),
+	})
+}
+
+func (s *Server) listWorkflowRuns(w http.ResponseWriter, r *http.Request, repo *Repository) {
+	query := r.URL.Query()
+
+	runs := []*github.WorkflowRun{}
+
+	// Most recent runs first
+	for _, run := range slices.Backward(repo.runs) {
+		if !strings.HasSuffix(run.GetPath(), "/"+r.PathValue("workflow")) {
+			continue
+		}
+
+		if branch := query.Get("branch"); branch != "" && run.GetHeadBranch() != branch {
+			continue
+		}
+
+		if event := query.Get("event"); event != "" && run.GetEvent() != event {
+			continue
+		}
+
+		runs = append(runs, run)
+	}
+
+	writeJSON(w, http.StatusOK, &github.WorkflowRuns{
+		TotalCount:   github.Ptr(len(runs)),
+		WorkflowRuns: runs,
+	})
+}
+
+func (s *Server) getWorkflowRun(w http.ResponseWriter, r *http.Request, repo *Repository) {
+	for _, run := range repo.runs {
+		if strconv.FormatInt(run.GetID(), 10) == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, run)
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+// commitSHA returns a SHA identifying the current files of the repository.
+func (r *Repository) commitSHA() string {
+	h := sha1.New()
+
+	for _, name := range slices.Sorted(maps.Keys(r.Files)) {
+		fmt.Fprintf(h, "%s\x00%s\x00", name, r.Files[name])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// blobSHA returns the Git object ID of a file.
+// dirs returns the directories of the repository (including the root).
+func (r *Repository) dirs() []string {
+	dirs := map[string]bool{".": true}
+
+	for name := range r.Files {
+		for dir := path.Dir(name); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(dirs))
+}
+
+// treeSHA returns the Git object ID of the tree of a directory (every file is a regular, non-executable file).
+func (r *Repository) treeSHA(dir string) string {
+	type entry struct {
+		name string
+		mode string
+		sha  string
+	}
+
+	var entries []entry
+
+	for _, name := range r.dirs() {
+		if name != "." && path.Dir(name) == dir {
+			entries = append(entries, entry{name: path.Base(name) + "/", mode: "40000", sha: r.treeSHA(name)})
+		}
+	}
+
+	for name, content := range r.Files {
+		if path.Dir(name) == dir {
+			entries = append(entries, entry{name: path.Base(name), mode: "100644", sha: blobSHA(content)})
+		}
+	}
+
+	// Git sorts entries by name, as if the names of trees ended with a slash
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.name, b.name) })
+
+	var content bytes.Buffer
+
+	for _, e := range entries {
+		sha, _ := hex.DecodeString(e.sha)
+
+		fmt.Fprintf(&content, "%s %s\x00", e.mode, strings.TrimSuffix(e.name, "/"))
+		content.Write(sha)
+	}
+
+	return fmt.Sprintf("%x", sha1.Sum(fmt.Appendf(nil, "tree %d\x00%s", content.Len(), content.Bytes())))
+}
+
+func blobSHA(content string) string {
+	return fmt.Sprintf("%x", sha1.Sum(fmt.Appendf(nil, "blob %d\x00%s", len(content), content)))
+}
+
+func newRepository(repo *Repository) *github.Repository {
+	return &github.Repository{
+		Owner:         &github.User{Login: github.Ptr(repo.Owner)},
+		Name:          github.Ptr(repo.Name),
+		FullName:      github.Ptr(repo.FullName()),
+		DefaultBranch: github.Ptr(repo.DefaultBranch),
+	}
+}
+
+func newMilestone(milestone Milestone) *github.Milestone {
+	return &github.Milestone{
+		Number: github.Ptr(milestone.Number),
+		Title:  github.Ptr(milestone.Title),
+		State:  github.Ptr("open"),
+	}
+}
+
+func newIssue(repo *Repository, issue *Issue) *github.Issue {
+	i := &github.Issue{
+		Number: github.Ptr(issue.Number),
+		Title:  github.Ptr(issue.Title),
+		State:  github.Ptr(issue.State),
+		Locked: github.Ptr(issue.Locked),
+		Labels: []*github.Label{},
+	}
+
+	if issue.StateReason != "" {
+		i.StateReason = github.Ptr(issue.StateReason)
+	}
+
+	if issue.Author != "" {
+		i.User = &github.User{Login: github.Ptr(issue.Author)}
+	}
+
+	for _, label := range issue.Labels {
+		i.Labels = append(i.Labels, &github.Label{Name: github.Ptr(label)})
+	}
+
+	for _, assignee := range issue.Assignees {
+		i.Assignees = append(i.Assignees, &github.User{Login: github.Ptr(assignee)})
+	}
+
+	for _, milestone := range repo.Milestones {
+		if milestone.Number == issue.Milestone {
+			i.Milestone = newMilestone(milestone)
+		}
+	}
+
+	if issue.PullRequest {
+		i.PullRequestLinks = &github.PullRequestLinks{
+			URL: github.Ptr(fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d", repo.FullName(), issue.Number)),
+		}
+	}
+
+	return i
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,73 @@
+package octoslashtest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/octoslashtest"
+)
+
+type GreetArgs struct {
+	Name string `arg:"name"`
+}
+
+func TestServer(t *testing.T) {
+	server := octoslashtest.NewServer(t)
+
+	repo := server.AddRepository(&octoslashtest.Repository{
+		Owner: "owner",
+		Name:  "repo",
+		Issues: map[int]*octoslashtest.Issue{
+			12: {Number: 12},
+		},
+	})
+
+	var registry octoslash.Registry
+
+	octoslash.Register(
+		&registry,
+		octoslash.HandlerFunc[GreetArgs](
+			func(ctx context.Context, cmd octoslash.Command[GreetArgs]) error {
+				_, _, err := cmd.Client.Issues.CreateComment(
+					ctx,
+					cmd.Event.GetRepo().GetOwner().GetLogin(),
+					cmd.Event.GetRepo().GetName(),
+					cmd.Event.GetIssue().GetNumber(),
+					&github.IssueComment{
+						Body: github.Ptr(fmt.Sprintf("Hello, %s!", cmd.Args.Name)),
+					},
+				)
+
+				return err
+			},
+		),
+	)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	handler := octoslash.EventHandler{
+		Dispatcher: command.CobraDispatcher{
+			Authorizer:      octoslashtest.AllowAll(),
+			CommandProvider: registry.NewCommandProvider(server.Client(), logger),
+		},
+	}
+
+	event := octoslashtest.NewIssueCommentEvent(
+		"/greet world",
+		octoslashtest.WithIssue(repo.Issues[12]),
+		octoslashtest.WithUser("alice", 2),
+	)
+
+	results, _ := handler.Execute(t.Context(), event)
+
+	octoslashtest.AssertResults(t, results, octoslash.ResultSucceeded)
+	octoslashtest.AssertComment(t, repo.Issues[12], "Hello, world!")
+	octoslashtest.AssertRequests(t, server, "POST /repos/owner/repo/issues/12/comments")
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Error is returned when a command cannot be parsed.
+type Error struct {
+	// Line of the error (1-based, 0 if unknown).
+	Line int
+
+	// Column of the error (1-based, in bytes, 0 if unknown).
+	Column int
+
+	// Message is a human explanation of the error.
+	Message string
+
+	// Err is the underlying error (if any).
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// offset moves the position of the error by the position of the command in the comment.
+func (e *Error) offset(cmd Command) {
+	if e.Line == 0 {
+		return
+	}
+
+	if e.Line == 1 {
+		e.Column += cmd.Column - 1
+	}
+
+	e.Line += cmd.Line - 1
+}
+
+func newNodeError(node syntax.Node, message string) *Error {
+	pos := node.Pos()
+
+	return &Error{
+		Line:    int(pos.Line()),
+		Column:  int(pos.Col()),
+		Message: message,
+	}
+}
+
+// newSyntaxError explains shell syntax errors.
+func newSyntaxError(err error) *Error {
+	var parseErr syntax.ParseError
+	if !errors.As(err, &parseErr) {
+		return &Error{Message: err.Error(), Err: err}
+	}
+
+	return &Error{
+		Line:    int(parseErr.Pos.Line()),
+		Column:  int(parseErr.Pos.Col()),
+		Message: explainSyntaxError(parseErr.Text),
+		Err:     err,
+	}
+}
+
+func explainSyntaxError(text string) string {
+	if _, quote, ok := strings.Cut(text, "without closing quote "); ok {
+		if quote == "`" {
+			return "unterminated backquote: add the closing ` or escape it with a backslash"
+		}
+
+		return fmt.Sprintf("unterminated quote: add the closing %s or escape it with a backslash", quote)
+	}
+
+	if _, delimiter, ok := strings.Cut(text, "unclosed here-document "); ok {
+		return fmt.Sprintf(
+			"unterminated heredoc: add a line containing only %s",
+			strings.Trim(delimiter, `'"`),
+		)
+	}
+
+	if _, pair, ok := strings.Cut(text, "without matching "); ok {
+		if opening, closing, ok := strings.Cut(pair, " with "); ok {
+			return fmt.Sprintf("unterminated %s: add the closing %s", opening, closing)
+		}
+	}
+
+	if strings.Contains(text, "encountered )") || strings.Contains(text, "must be followed by )") {
+		return "parentheses must be quoted or escaped with a backslash"
+	}
+
+	return "invalid syntax: " + text
+}
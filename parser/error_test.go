@@ -0,0 +1,112 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sagikazarmark/octoslash/parser"
+)
+
+func TestParser_ParseCommand_Error(t *testing.T) {
+	testCases := []struct {
+		name    string
+		comment string
+		line    int
+		column  int
+		message string
+	}{
+		{
+			name:    "unterminated quote",
+			comment: "Thanks!\n\n  /label \"needs triage",
+			line:    3,
+			column:  10,
+			message: "unterminated quote: add the closing \" or escape it with a backslash",
+		},
+		{
+			name:    "unterminated heredoc",
+			comment: "/comment <<EOF\nhello",
+			line:    1,
+			column:  10,
+			message: "unterminated heredoc: add a line containing only EOF",
+		},
+		{
+			name:    "unterminated heredoc followed by a command",
+			comment: "/comment <<EOF\nhello\n/close",
+			line:    1,
+			column:  10,
+			message: "unterminated heredoc: add a line containing only EOF",
+		},
+		{
+			name:    "pipe",
+			comment: "/label a | b",
+			line:    1,
+			column:  10,
+			message: "pipes (|) are not allowed: quote or escape | to use it in an argument",
+		},
+		{
+			name:    "command list",
+			comment: "/label a && /close",
+			line:    1,
+			column:  10,
+			message: "command lists (&& and ||) are not allowed: put every command on its own line",
+		},
+		{
+			name:    "multiple commands",
+			comment: "/label a; close",
+			line:    1,
+			column:  11,
+			message: "only one command is allowed per line: put every command on its own line",
+		},
+		{
+			name:    "redirect",
+			comment: "/label a \\\n  > b",
+			line:    2,
+			column:  3,
+			message: "redirects are not allowed: quote or escape < and > to use them in an argument",
+		},
+		{
+			name:    "mention",
+			comment: "@octoslash  label 'a",
+			line:    1,
+			column:  19,
+			message: "unterminated quote: add the closing ' or escape it with a backslash",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			trigger := parser.DefaultTrigger
+			if strings.HasPrefix(testCase.comment, "@") {
+				trigger = parser.Trigger{Style: parser.TriggerMention, Name: "octoslash"}
+			}
+
+			commands := trigger.Scan(strings.NewReader(testCase.comment))
+			// The erroneous command is always the first one
+			if len(commands) == 0 {
+				t.Fatal("expected a command")
+			}
+
+			_, err := parser.NewParser().ParseCommand(commands[0])
+
+			var parseErr *parser.Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a parse error, got %v", err)
+			}
+
+			if parseErr.Line != testCase.line || parseErr.Column != testCase.column {
+				t.Errorf(
+					"expected position %d:%d, got %d:%d",
+					testCase.line,
+					testCase.column,
+					parseErr.Line,
+					parseErr.Column,
+				)
+			}
+
+			if parseErr.Message != testCase.message {
+				t.Errorf("expected message %q, got %q", testCase.message, parseErr.Message)
+			}
+		})
+	}
+}
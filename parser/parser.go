@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"bytes"
 	"errors"
 	"io"
+	"strings"
 
 	"mvdan.cc/sh/v3/expand"
 	"mvdan.cc/sh/v3/syntax"
@@ -18,21 +20,43 @@ func NewParser() *Parser {
 	}
 }
 
+// Parse parses a command into arguments using shell syntax.
+//
+// Heredoc bodies (<<EOF) are appended to the arguments (one argument each) verbatim,
+// without the trailing newline and without expanding anything (as if the delimiter was quoted).
+//
+// Syntax errors are returned as [*Error] (with positions relative to the input).
 func (p *Parser) Parse(r io.Reader) ([]string, error) {
-	file, err := p.parser.Parse(r, "")
+	src, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
+	file, err := p.parser.Parse(bytes.NewReader(src), "")
+	if err != nil {
+		return nil, newSyntaxError(err)
+	}
+
 	if len(file.Stmts) == 0 {
-		return nil, errors.New("empty input")
+		return nil, &Error{Message: "empty command"}
 	}
 
-	cmd, ok := file.Stmts[0].Cmd.(*syntax.CallExpr)
-	if !ok {
-		return nil, errors.New("not a call expression")
+	stmt := file.Stmts[0]
+
+	err = checkStmt(stmt)
+	if err != nil {
+		return nil, err
 	}
 
+	if len(file.Stmts) > 1 {
+		return nil, newNodeError(
+			file.Stmts[1],
+			"only one command is allowed per line: put every command on its own line",
+		)
+	}
+
+	cmd := stmt.Cmd.(*syntax.CallExpr)
+
 	printer := syntax.NewPrinter()
 
 	config := &expand.Config{
@@ -46,11 +70,124 @@ func (p *Parser) Parse(r io.Reader) ([]string, error) {
 	for _, word := range cmd.Args {
 		expanded, err := expand.Literal(config, word)
 		if err != nil {
-			return nil, err
+			return nil, &Error{
+				Line:    int(word.Pos().Line()),
+				Column:  int(word.Pos().Col()),
+				Message: err.Error(),
+				Err:     err,
+			}
 		}
 
 		args = append(args, expanded)
 	}
 
+	for _, redirect := range stmt.Redirs {
+		if redirect.Op != syntax.Hdoc && redirect.Op != syntax.DashHdoc {
+			continue
+		}
+
+		delimiter, err := expand.Literal(config, redirect.Word)
+		if err != nil {
+			return nil, &Error{
+				Line:    int(redirect.Word.Pos().Line()),
+				Column:  int(redirect.Word.Pos().Col()),
+				Message: err.Error(),
+				Err:     err,
+			}
+		}
+
+		args = append(args, heredocBody(string(src), redirect, delimiter))
+	}
+
 	return args, nil
 }
+
+// ParseCommand parses a command found in a comment.
+//
+// Unlike [Parser.Parse], positions of errors are relative to the comment.
+func (p *Parser) ParseCommand(cmd Command) ([]string, error) {
+	args, err := p.Parse(strings.NewReader(cmd.Text))
+
+	var parseErr *Error
+	if errors.As(err, &parseErr) {
+		parseErr.offset(cmd)
+	}
+
+	return args, err
+}
+
+// checkStmt makes sure a statement is a single command with arguments (and optionally heredocs).
+func checkStmt(stmt *syntax.Stmt) error {
+	if stmt.Negated {
+		return newNodeError(stmt, "negation (!) is not allowed")
+	}
+
+	if stmt.Background || stmt.Coprocess {
+		return newNodeError(stmt, "running commands in the background (&) is not allowed")
+	}
+
+	switch cmd := stmt.Cmd.(type) {
+	case *syntax.CallExpr:
+		if len(cmd.Assigns) > 0 {
+			return newNodeError(cmd.Assigns[0], "variable assignments are not allowed")
+		}
+
+	case *syntax.BinaryCmd:
+		if cmd.Op == syntax.Pipe || cmd.Op == syntax.PipeAll {
+			return &Error{
+				Line:    int(cmd.OpPos.Line()),
+				Column:  int(cmd.OpPos.Col()),
+				Message: "pipes (|) are not allowed: quote or escape | to use it in an argument",
+			}
+		}
+
+		return &Error{
+			Line:    int(cmd.OpPos.Line()),
+			Column:  int(cmd.OpPos.Col()),
+			Message: "command lists (&& and ||) are not allowed: put every command on its own line",
+		}
+
+	default:
+		return newNodeError(
+			stmt,
+			"only simple commands are allowed (no subshells, blocks, loops, conditionals or functions)",
+		)
+	}
+
+	for _, redirect := range stmt.Redirs {
+		if redirect.Op != syntax.Hdoc && redirect.Op != syntax.DashHdoc {
+			return &Error{
+				Line:    int(redirect.OpPos.Line()),
+				Column:  int(redirect.OpPos.Col()),
+				Message: "redirects are not allowed: quote or escape < and > to use them in an argument",
+			}
+		}
+	}
+
+	return nil
+}
+
+// heredocBody returns the raw body of a heredoc.
+func heredocBody(src string, redirect *syntax.Redirect, delimiter string) string {
+	if redirect.Hdoc == nil {
+		return ""
+	}
+
+	var lines []string
+
+	for line := range strings.Lines(src[redirect.Hdoc.Pos().Offset():]) {
+		line = strings.TrimSuffix(line, "\n")
+
+		if redirect.Op == syntax.DashHdoc {
+			line = strings.TrimLeft(line, "\t")
+		}
+
+		if line == delimiter {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -62,6 +62,36 @@ func TestParser_Parse(t *testing.T) {
 				"test\\ file",
 			},
 		},
+		{
+			name:     "line continuation",
+			input:    "label a \\\n  b",
+			expected: []string{"label", "a", "b"},
+		},
+		{
+			name:     "heredoc",
+			input:    "comment <<EOF\nThanks for the `fix` in $PR!\n\n  Indented\nEOF",
+			expected: []string{"comment", "Thanks for the `fix` in $PR!\n\n  Indented"},
+		},
+		{
+			name:     "heredoc with quoted delimiter after arguments",
+			input:    "comment --as bot <<'END' extra\nhello\nEND\n",
+			expected: []string{"comment", "--as", "bot", "extra", "hello"},
+		},
+		{
+			name:     "heredoc stripping tabs",
+			input:    "comment <<-EOF\n\thello\n\t\tworld\n\tEOF",
+			expected: []string{"comment", "hello\nworld"},
+		},
+		{
+			name:     "empty heredoc",
+			input:    "comment <<EOF\nEOF",
+			expected: []string{"comment", ""},
+		},
+		{
+			name:    "unterminated heredoc",
+			input:   "comment <<EOF\nhello",
+			wantErr: true,
+		},
 		{
 			name:    "empty input",
 			input:   "",
@@ -2,36 +2,133 @@ package parser
 
 import (
 	"bufio"
-	"bytes"
 	"io"
 	"strings"
+	"unicode"
 )
 
 // ScanString finds slash commands in the given string
 func ScanString(text string) []string {
-	return ScanReader(strings.NewReader(text))
+	return DefaultTrigger.ScanString(text)
 }
 
 // ScanBytes finds slash commands in the given byte slice
 func ScanBytes(data []byte) []string {
-	return ScanReader(bytes.NewReader(data))
+	return DefaultTrigger.ScanBytes(data)
 }
 
 // ScanReader reads from an io.Reader and extracts slash commands line by line
 func ScanReader(r io.Reader) []string {
+	return DefaultTrigger.ScanReader(r)
+}
+
+// ScanReader reads from an io.Reader and extracts commands line by line.
+//
+// See [Trigger.Scan] for details.
+func (t Trigger) ScanReader(r io.Reader) []string {
 	var commands []string
-	scanner := bufio.NewScanner(r)
 
+	for _, cmd := range t.Scan(r) {
+		commands = append(commands, cmd.Text)
+	}
+
+	return commands
+}
+
+// Command is a command found in a comment.
+type Command struct {
+	// Text of the command (without the trigger).
+	Text string
+
+	// Line of the comment the command starts on (1-based).
+	Line int
+
+	// Column of the line the command text starts at (1-based, in bytes).
+	Column int
+}
+
+// Scan reads from an io.Reader and extracts commands line by line.
+//
+// A command continues on the next line if it ends with a backslash,
+// and heredoc bodies (/cmd <<EOF ... EOF) are included in the command.
+//
+// Lines of a heredoc body are never treated as commands,
+// unless the heredoc is unterminated: then the body ends before the next command
+// (so the command fails to parse instead of swallowing the rest of the comment).
+func (t Trigger) Scan(r io.Reader) []Command {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if cmd := extractCommandFromLine(line); cmd != "" {
-			commands = append(commands, cmd)
+		lines = append(lines, scanner.Text())
+	}
+
+	var commands []Command
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		text := t.extractCommand(line)
+		if text == "" {
+			continue
+		}
+
+		cmd := Command{
+			Text: text,
+			Line: i + 1,
+			// The command is always at the end of the line (except trailing whitespace)
+			Column: len(strings.TrimRightFunc(line, unicode.IsSpace)) - len(text) + 1,
+		}
+
+		// A trailing backslash continues the command on the next line
+		for hasLineContinuation(cmd.Text) && i+1 < len(lines) {
+			i++
+			cmd.Text += "\n" + strings.TrimRightFunc(lines[i], unicode.IsSpace)
 		}
+
+		// Heredoc bodies belong to the command (up to and including the delimiter line)
+		for _, delimiter := range heredocDelimiters(cmd.Text) {
+			end, ok := t.heredocEnd(lines, i+1, delimiter)
+
+			for _, line := range lines[i+1 : end] {
+				cmd.Text += "\n" + line
+			}
+
+			i = end - 1
+
+			if !ok {
+				break
+			}
+
+			cmd.Text += "\n" + delimiter
+			i++
+		}
+
+		commands = append(commands, cmd)
 	}
 
 	return commands
 }
 
+// heredocEnd returns the index of the line terminating a heredoc body starting at index start.
+//
+// If the heredoc is unterminated, it returns the index of the next command (or the number of lines) and false.
+func (t Trigger) heredocEnd(lines []string, start int, delimiter string) (int, bool) {
+	for i := start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == delimiter {
+			return i, true
+		}
+	}
+
+	for i := start; i < len(lines); i++ {
+		if t.extractCommand(lines[i]) != "" {
+			return i, false
+		}
+	}
+
+	return len(lines), false
+}
+
 // extractCommandFromLine finds the first slash command in a single line
 // Commands must be at the beginning of the line (after optional whitespace)
 func extractCommandFromLine(line string) string {
@@ -56,6 +153,79 @@ func extractCommandFromLine(line string) string {
 	return ""
 }
 
+// hasLineContinuation checks if a line ends with an unescaped backslash.
+func hasLineContinuation(line string) bool {
+	var n int
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// heredocDelimiters returns the delimiters of heredocs (<<EOF) in a command (in order).
+//
+// Redirections in quotes and comments are ignored.
+func heredocDelimiters(cmd string) []string {
+	var delimiters []string
+
+	var quote byte
+
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+
+		case c == '\\':
+			i++
+
+		case c == '\'' || c == '"':
+			quote = c
+
+		case c == '#' && (i == 0 || isWhitespace(cmd[i-1])):
+			// The rest of the line is a comment
+			for i < len(cmd) && cmd[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(cmd[i:], "<<<"):
+			// Here-string
+			i += 2
+
+		case strings.HasPrefix(cmd[i:], "<<"):
+			i += 2
+
+			if i < len(cmd) && cmd[i] == '-' {
+				i++
+			}
+
+			for i < len(cmd) && (cmd[i] == ' ' || cmd[i] == '\t') {
+				i++
+			}
+
+			start := i
+			for i < len(cmd) && !isWhitespace(cmd[i]) && !strings.ContainsRune(";&|<>()", rune(cmd[i])) {
+				i++
+			}
+
+			delimiter := strings.NewReplacer("'", "", `"`, "", `\`, "").Replace(cmd[start:i])
+			if delimiter != "" {
+				delimiters = append(delimiters, delimiter)
+			}
+
+			i--
+		}
+	}
+
+	return delimiters
+}
+
 // isWhitespace checks if a character is whitespace
 func isWhitespace(c byte) bool {
 	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
@@ -256,3 +256,61 @@ func TestExtractCommandFromLine(t *testing.T) {
 		})
 	}
 }
+
+func TestScanString_MultiLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "line continuation",
+			text:     "/label a \\\n  b \\  \n c\n/close",
+			expected: []string{"label a \\\n  b \\\n c", "close"},
+		},
+		{
+			name:     "escaped backslash is not a continuation",
+			text:     "/label a\\\\\n/close",
+			expected: []string{"label a\\\\", "close"},
+		},
+		{
+			name:     "heredoc",
+			text:     "Hi\n/comment <<EOF\nThanks!\n/close\n  EOF\n/close",
+			expected: []string{"comment <<EOF\nThanks!\n/close\nEOF", "close"},
+		},
+		{
+			name:     "multiple heredocs",
+			text:     "/cmd <<A <<-'B'\na\nA\nb\nB",
+			expected: []string{"cmd <<A <<-'B'\na\nA\nb\nB"},
+		},
+		{
+			name:     "quoted heredoc operator",
+			text:     "/comment \"a <<EOF\"\n/close",
+			expected: []string{"comment \"a <<EOF\"", "close"},
+		},
+		{
+			name:     "unterminated heredoc stops at the next command",
+			text:     "/comment <<EOF\nThanks!\n\n/close\nBye",
+			expected: []string{"comment <<EOF\nThanks!\n", "close"},
+		},
+		{
+			name:     "unterminated heredoc consumes the rest of the comment without commands",
+			text:     "/comment <<EOF\nThanks!\nBye",
+			expected: []string{"comment <<EOF\nThanks!\nBye"},
+		},
+		{
+			name:     "unterminated second heredoc",
+			text:     "/cmd <<A <<B\na\nA\nb\n/close",
+			expected: []string{"cmd <<A <<B\na\nA\nb", "close"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ScanString(tt.text)
+			if !stringSlicesEqual(result, tt.expected) {
+				t.Errorf("ScanString() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
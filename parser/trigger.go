@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// TriggerStyle is the way commands are written in comments.
+type TriggerStyle int
+
+const (
+	// TriggerSlash is a plain slash command: /cmd args.
+	TriggerSlash TriggerStyle = iota
+
+	// TriggerNamespaced is a slash command namespaced by the bot name: /name cmd args.
+	TriggerNamespaced
+
+	// TriggerMention is a command addressed to the bot by mentioning it: @name cmd args.
+	TriggerMention
+)
+
+// Trigger determines which lines of a comment are commands.
+type Trigger struct {
+	Style TriggerStyle
+
+	// Name of the bot (required by [TriggerNamespaced] and [TriggerMention]).
+	//
+	// Matched case-insensitively.
+	Name string
+}
+
+// DefaultTrigger is the plain slash command trigger.
+var DefaultTrigger = Trigger{Style: TriggerSlash}
+
+// ParseTrigger parses a trigger in the form it is written in comments:
+// "/" for plain slash commands, "/name" for namespaced slash commands and "@name" for mentions.
+func ParseTrigger(s string) (Trigger, error) {
+	switch {
+	case s == "/" || s == "":
+		return DefaultTrigger, nil
+
+	case strings.HasPrefix(s, "/") && isTriggerName(s[1:]):
+		return Trigger{Style: TriggerNamespaced, Name: s[1:]}, nil
+
+	case strings.HasPrefix(s, "@") && isTriggerName(s[1:]):
+		return Trigger{Style: TriggerMention, Name: s[1:]}, nil
+
+	default:
+		return DefaultTrigger, fmt.Errorf(
+			"invalid trigger %q: expected /, /name or @name",
+			s,
+		)
+	}
+}
+
+func (t Trigger) String() string {
+	switch t.Style {
+	case TriggerNamespaced:
+		return "/" + t.Name
+
+	case TriggerMention:
+		return "@" + t.Name
+
+	default:
+		return "/"
+	}
+}
+
+// ScanString finds commands in the given string.
+func (t Trigger) ScanString(text string) []string {
+	return t.ScanReader(strings.NewReader(text))
+}
+
+// ScanBytes finds commands in the given byte slice.
+func (t Trigger) ScanBytes(data []byte) []string {
+	return t.ScanReader(bytes.NewReader(data))
+}
+
+// MayContainCommands reports whether text may contain commands with any trigger
+// (ie. a line starts with a slash or a mention).
+//
+// Use it to skip loading the trigger (eg. from configuration) for comments that cannot contain commands.
+func MayContainCommands(text string) bool {
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "/") || strings.HasPrefix(line, "@") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractCommand returns the command (without the trigger) if the line starts with the trigger.
+func (t Trigger) extractCommand(line string) string {
+	switch t.Style {
+	case TriggerNamespaced, TriggerMention:
+		prefix := "/"
+		if t.Style == TriggerMention {
+			prefix = "@"
+		}
+
+		line = strings.TrimSpace(line)
+
+		rest, ok := strings.CutPrefix(line, prefix)
+		if !ok || t.Name == "" || len(rest) < len(t.Name) ||
+			!strings.EqualFold(rest[:len(t.Name)], t.Name) {
+			return ""
+		}
+
+		rest = rest[len(t.Name):]
+
+		// The name must be followed by whitespace (eg. @octoslash-ci is a different bot)
+		if rest == "" || !isWhitespace(rest[0]) {
+			return ""
+		}
+
+		return strings.TrimSpace(rest)
+
+	default:
+		return extractCommandFromLine(line)
+	}
+}
+
+// isTriggerName checks if s is a valid bot name (a GitHub login or app slug).
+func isTriggerName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' ||
+			c == '_' || c == '[' || c == ']') {
+			return false
+		}
+	}
+
+	return true
+}
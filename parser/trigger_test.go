@@ -0,0 +1,103 @@
+package parser
+
+import "testing"
+
+func TestParseTrigger(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Trigger
+		wantErr  bool
+	}{
+		{input: "", expected: DefaultTrigger},
+		{input: "/", expected: DefaultTrigger},
+		{input: "/octoslash", expected: Trigger{Style: TriggerNamespaced, Name: "octoslash"}},
+		{input: "@octoslash[bot]", expected: Trigger{Style: TriggerMention, Name: "octoslash[bot]"}},
+		{input: "octoslash", wantErr: true},
+		{input: "@", wantErr: true},
+		{input: "/octo slash", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseTrigger(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTrigger() expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTrigger() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("ParseTrigger() = %v, expected %v", result, tt.expected)
+			}
+
+			if result.String() != tt.input && tt.input != "" {
+				t.Errorf("String() = %q, expected %q", result.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestTrigger_ScanString(t *testing.T) {
+	text := "/label a\n/octoslash label b\n@octoslash label c\n  @OctoSlash close\n" +
+		"@octoslash-ci label d\n/octoslash\n@octoslash\n/retest\nping @octoslash label e"
+
+	tests := []struct {
+		name     string
+		trigger  Trigger
+		expected []string
+	}{
+		{
+			name:     "slash",
+			trigger:  DefaultTrigger,
+			expected: []string{"label a", "octoslash label b", "octoslash", "retest"},
+		},
+		{
+			name:     "namespaced",
+			trigger:  Trigger{Style: TriggerNamespaced, Name: "octoslash"},
+			expected: []string{"label b"},
+		},
+		{
+			name:     "mention",
+			trigger:  Trigger{Style: TriggerMention, Name: "octoslash"},
+			expected: []string{"label c", "close"},
+		},
+		{
+			name:     "mention without name",
+			trigger:  Trigger{Style: TriggerMention},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.trigger.ScanString(text)
+			if !stringSlicesEqual(result, tt.expected) {
+				t.Errorf("ScanString() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMayContainCommands(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{text: "LGTM", expected: false},
+		{text: "ping @octoslash label a\nsee /docs", expected: false},
+		{text: "Thanks!\n/label a", expected: true},
+		{text: "  @octoslash close", expected: true},
+	}
+
+	for _, tt := range tests {
+		if result := MayContainCommands(tt.text); result != tt.expected {
+			t.Errorf("MayContainCommands(%q) = %v, expected %v", tt.text, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package octoslash
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/spf13/cobra"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+// Command is an invocation of a command registered in a [Registry].
+type Command[T any] struct {
+	Event  github.IssueCommentEvent
+	Client *github.Client
+	Logger *slog.Logger
+
+	// Args contains the parsed arguments of the command.
+	Args T
+}
+
+// Handler handles a command registered in a [Registry].
+type Handler[T any] interface {
+	Handle(ctx context.Context, cmd Command[T]) error
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as a [Handler].
+type HandlerFunc[T any] func(ctx context.Context, cmd Command[T]) error
+
+// Handle calls fn(ctx, cmd).
+func (fn HandlerFunc[T]) Handle(ctx context.Context, cmd Command[T]) error {
+	return fn(ctx, cmd)
+}
+
+// Registry collects typed commands.
+//
+// A Registry can be used as a provider on its own, or combined with the builtin commands (see builtin.Provider).
+type Registry struct {
+	commands []commandFactory
+}
+
+// commandFactory creates a Cobra command for an event.
+type commandFactory func(
+	event github.IssueCommentEvent,
+	client *github.Client,
+	logger *slog.Logger,
+) *cobra.Command
+
+// CommandOption configures a command registered in a [Registry].
+type CommandOption func(o *commandOptions)
+
+type commandOptions struct {
+	name    string
+	aliases []string
+	short   string
+	long    string
+}
+
+// WithName sets the name of the command.
+//
+// The name is also used as the authorization action of the command.
+// Defaults to the name of the argument type in kebab case without an "Args" suffix (eg. DeployArgs -> deploy).
+func WithName(name string) CommandOption {
+	return func(o *commandOptions) {
+		o.name = name
+	}
+}
+
+// WithAliases sets the aliases of the command.
+func WithAliases(aliases ...string) CommandOption {
+	return func(o *commandOptions) {
+		o.aliases = aliases
+	}
+}
+
+// WithShort sets the short description of the command.
+func WithShort(short string) CommandOption {
+	return func(o *commandOptions) {
+		o.short = short
+	}
+}
+
+// WithLong sets the long description of the command.
+func WithLong(long string) CommandOption {
+	return func(o *commandOptions) {
+		o.long = long
+	}
+}
+
+// Register registers a command in the registry.
+//
+// Arguments are parsed into T (which must be a struct) according to its struct tags:
+//
+//   - arg:"name": positional argument (required unless the ",optional" option is set; a []string field consumes the remaining arguments)
+//   - flag:"name": flag
+//   - short:"f": shorthand of a flag
+//   - help:"...": description of a flag or positional argument
+//
+// Supported field types are string, int, bool (flags only) and []string.
+// Names default to the field name in kebab case.
+//
+// Arguments and flags are also exposed to authorization as context attributes (by name).
+//
+// Register panics if T is not a valid argument struct.
+func Register[T any](r *Registry, handler Handler[T], opts ...CommandOption) {
+	t := reflect.TypeFor[T]()
+
+	spec, err := newArgSpec(t)
+	if err != nil {
+		panic(fmt.Sprintf("octoslash: registering command for %s: %v", t, err))
+	}
+
+	o := commandOptions{
+		name: kebabCase(strings.TrimSuffix(t.Name(), "Args")),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.name == "" {
+		panic(fmt.Sprintf("octoslash: registering command for %s: name is required", t))
+	}
+
+	long := o.long
+	if argsHelp := spec.long(); argsHelp != "" {
+		long = strings.TrimSpace(long + "\n\n" + argsHelp)
+	}
+
+	r.commands = append(r.commands, func(
+		event github.IssueCommentEvent,
+		client *github.Client,
+		logger *slog.Logger,
+	) *cobra.Command {
+		var args T
+
+		v := reflect.ValueOf(&args).Elem()
+
+		validateArgs := spec.validator()
+
+		cmd := &cobra.Command{
+			Use:     spec.use(o.name),
+			Aliases: o.aliases,
+			Short:   o.short,
+			Long:    long,
+
+			// Bind arguments while validating them (before authorization), so policies can decide based on them
+			Args: func(cmd *cobra.Command, positional []string) error {
+				err := validateArgs(cmd, positional)
+				if err != nil {
+					return err
+				}
+
+				err = spec.bindArgs(positional, v)
+				if err != nil {
+					return err
+				}
+
+				cmd.SetContext(command.WithAuthorizationContext(cmd.Context(), spec.attributes(v)))
+
+				return nil
+			},
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return handler.Handle(cmd.Context(), Command[T]{
+					Event:  event,
+					Client: client,
+					Logger: logger,
+					Args:   args,
+				})
+			},
+		}
+
+		spec.bindFlags(cmd.Flags(), v)
+
+		return cmd
+	})
+}
+
+// NewCommandProvider returns a [command.CommandProvider] for the registered commands.
+//
+// It allows using a Registry as a provider on its own.
+func (r *Registry) NewCommandProvider(
+	client *github.Client,
+	logger *slog.Logger,
+) command.CommandProvider {
+	return registryCommandProvider{
+		registry: r,
+		client:   client,
+		logger:   logger,
+	}
+}
+
+type registryCommandProvider struct {
+	registry *Registry
+	client   *github.Client
+	logger   *slog.Logger
+}
+
+func (p registryCommandProvider) NewCommand(event github.IssueCommentEvent) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "octoslash",
+		Short: "Slash commands for GitHub issues and pull requests",
+	}
+
+	for _, newCommand := range p.registry.commands {
+		rootCmd.AddCommand(newCommand(event, p.client, p.logger))
+	}
+
+	return rootCmd
+}
@@ -0,0 +1,167 @@
+package octoslash_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+
+	"github.com/sagikazarmark/octoslash"
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+type DeployArgs struct {
+	Environment string   `arg:"environment" help:"Environment to deploy to"`
+	Services    []string `arg:"services,optional"`
+	Force       bool     `flag:"force" short:"f"`
+	Replicas    int      `flag:"replicas"`
+}
+
+type recordingAuthorizer struct {
+	action  string
+	context map[string]any
+}
+
+func (a *recordingAuthorizer) Authorize(
+	ctx context.Context,
+	_ github.IssueCommentEvent,
+	action string,
+) error {
+	a.action = action
+	a.context = command.AuthorizationContextFromContext(ctx)
+
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	registry := &octoslash.Registry{}
+
+	var actual DeployArgs
+
+	octoslash.Register(
+		registry,
+		octoslash.HandlerFunc[DeployArgs](
+			func(_ context.Context, cmd octoslash.Command[DeployArgs]) error {
+				actual = cmd.Args
+
+				return nil
+			},
+		),
+		octoslash.WithShort("Deploy services"),
+	)
+
+	authorizer := &recordingAuthorizer{}
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer:      authorizer,
+		CommandProvider: registry.NewCommandProvider(nil, nil),
+	}
+
+	err := dispatcher.Dispatch(
+		t.Context(),
+		github.IssueCommentEvent{},
+		[]string{"deploy", "--force", "--replicas=3", "production", "api", "worker"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := DeployArgs{
+		Environment: "production",
+		Services:    []string{"api", "worker"},
+		Force:       true,
+		Replicas:    3,
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected args %+v, got %+v", expected, actual)
+	}
+
+	if authorizer.action != "deploy" {
+		t.Errorf("expected action %q, got %q", "deploy", authorizer.action)
+	}
+
+	if authorizer.context["environment"] != "production" {
+		t.Errorf("expected environment in authorization context, got %v", authorizer.context)
+	}
+}
+
+func TestRegister_MissingArgs(t *testing.T) {
+	registry := &octoslash.Registry{}
+
+	octoslash.Register(
+		registry,
+		octoslash.HandlerFunc[DeployArgs](func(context.Context, octoslash.Command[DeployArgs]) error {
+			return errors.New("should not be called")
+		}),
+	)
+
+	dispatcher := command.CobraDispatcher{
+		Authorizer:      &recordingAuthorizer{},
+		CommandProvider: registry.NewCommandProvider(nil, nil),
+	}
+
+	err := dispatcher.Dispatch(t.Context(), github.IssueCommentEvent{}, []string{"deploy"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestRegister_InvalidArgs(t *testing.T) {
+	type VariadicNotLast struct {
+		Rest  []string `arg:"rest"`
+		After string   `arg:"after"`
+	}
+
+	type UnexportedArg struct {
+		environment string `arg:"environment"`
+	}
+
+	type UnexportedFlag struct {
+		force bool `flag:"force"`
+	}
+
+	testCases := []struct {
+		name     string
+		register func(r *octoslash.Registry)
+	}{
+		{
+			name: "variadic argument not last",
+			register: func(r *octoslash.Registry) {
+				octoslash.Register(r, octoslash.HandlerFunc[VariadicNotLast](
+					func(context.Context, octoslash.Command[VariadicNotLast]) error { return nil },
+				))
+			},
+		},
+		{
+			name: "unexported argument",
+			register: func(r *octoslash.Registry) {
+				octoslash.Register(r, octoslash.HandlerFunc[UnexportedArg](
+					func(context.Context, octoslash.Command[UnexportedArg]) error { return nil },
+				))
+			},
+		},
+		{
+			name: "unexported flag",
+			register: func(r *octoslash.Registry) {
+				octoslash.Register(r, octoslash.HandlerFunc[UnexportedFlag](
+					func(context.Context, octoslash.Command[UnexportedFlag]) error { return nil },
+				))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+
+			testCase.register(&octoslash.Registry{})
+		})
+	}
+}
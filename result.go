@@ -0,0 +1,186 @@
+package octoslash
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/retry"
+)
+
+// ExecutionPolicy controls how [EventHandler] deals with failing commands in a comment.
+type ExecutionPolicy int
+
+const (
+	// StopOnError stops at the first failing command and skips the remaining ones.
+	//
+	// Commands that cannot be parsed are reported as invalid and skipped, but they do not stop the rest.
+	StopOnError ExecutionPolicy = iota
+
+	// ContinueOnError executes every command regardless of failures and aggregates the errors.
+	ContinueOnError
+
+	// AllOrNothing validates every command before executing any of them
+	// and only executes commands if all of them are valid.
+	// Once execution starts, it stops at the first failing command.
+	//
+	// Commands are parsed, then validated by the dispatcher (eg. resolved, argument-validated and authorized).
+	// The dispatcher must implement [CommandValidator], otherwise no command is executed.
+	AllOrNothing
+)
+
+// ParseExecutionPolicy parses the name of an execution policy.
+func ParseExecutionPolicy(s string) (ExecutionPolicy, error) {
+	switch s {
+	case "stop", "":
+		return StopOnError, nil
+
+	case "continue":
+		return ContinueOnError, nil
+
+	case "all-or-nothing":
+		return AllOrNothing, nil
+
+	default:
+		return StopOnError, fmt.Errorf("unknown execution policy: %q", s)
+	}
+}
+
+func (p ExecutionPolicy) String() string {
+	switch p {
+	case StopOnError:
+		return "stop"
+
+	case ContinueOnError:
+		return "continue"
+
+	case AllOrNothing:
+		return "all-or-nothing"
+
+	default:
+		return fmt.Sprintf("ExecutionPolicy(%d)", int(p))
+	}
+}
+
+// ResultStatus is the outcome of a command.
+type ResultStatus string
+
+const (
+	// ResultSucceeded means the command was executed successfully.
+	ResultSucceeded ResultStatus = "succeeded"
+
+	// ResultFailed means the command was executed (or at least dispatched) and failed.
+	ResultFailed ResultStatus = "failed"
+
+	// ResultInvalid means the command could not be parsed (or failed validation).
+	ResultInvalid ResultStatus = "invalid"
+
+	// ResultDenied means the command was not authorized.
+	ResultDenied ResultStatus = "denied"
+
+	// ResultRateLimited means the command failed because it hit a GitHub API rate limit.
+	// It may succeed if it is issued again after the limit resets.
+	ResultRateLimited ResultStatus = "rate-limited"
+
+	// ResultSkipped means the command was not executed because of a previous failure.
+	ResultSkipped ResultStatus = "skipped"
+)
+
+// Result is the outcome of a single command in a comment.
+type Result struct {
+	// Command is the raw command (without the leading slash).
+	Command string
+
+	// Args are the parsed arguments of the command (if parsing succeeded).
+	Args []string
+
+	// Name is the name of the command resolved by the dispatcher
+	// (eg. the command an alias refers to, see [command.CommandName]).
+	//
+	// Empty if the dispatcher did not resolve the command (eg. unknown commands).
+	Name string
+
+	Status ResultStatus
+
+	// Err is the reason of the failure (if any).
+	Err error
+
+	// Decision is the authorization decision of the command
+	// (nil if the command was not authorized, eg. it was rejected before authorization).
+	Decision *command.Decision
+}
+
+// setName records the resolved name of the command (keeping the name resolved earlier, eg. during validation).
+func (r *Result) setName(name string) {
+	if name != "" {
+		r.Name = name
+	}
+}
+
+func (r Result) err() error {
+	if r.Err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("/%s: %w", r.Command, r.Err)
+}
+
+// Results is the outcome of every command in a comment (in order).
+type Results []Result
+
+// Err returns the errors of every failed command joined together (or nil if there are none).
+func (r Results) Err() error {
+	var errs []error
+
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, result.err())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Count returns the number of results with the given status.
+func (r Results) Count(status ResultStatus) int {
+	var n int
+
+	for _, result := range r {
+		if result.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+// skip marks every result starting from index i as skipped (unless it already has an outcome, eg. it's invalid).
+func (r Results) skip(i int) {
+	for ; i < len(r); i++ {
+		if r[i].Status == "" {
+			r[i].Status = ResultSkipped
+		}
+	}
+}
+
+// failureStatus returns the status of a command that failed with err.
+//
+// Unauthorized commands are reported as denied, unknown commands as invalid,
+// commands that hit a rate limit as rate limited, everything else gets the fallback status.
+func failureStatus(err error, fallback ResultStatus) ResultStatus {
+	if errors.Is(err, command.ErrUnauthorized) {
+		return ResultDenied
+	}
+
+	var unknownErr *command.UnknownCommandError
+	if errors.As(err, &unknownErr) {
+		return ResultInvalid
+	}
+
+	var rateLimitErr *retry.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return ResultRateLimited
+	}
+
+	return fallback
+}
@@ -0,0 +1,322 @@
+// Package retry implements an HTTP transport for the GitHub API that retries transient failures
+// and waits for rate limits to reset.
+package retry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the default number of times a request is retried.
+	DefaultMaxRetries = 3
+
+	// DefaultMinBackoff is the default base of the exponential backoff between retries.
+	DefaultMinBackoff = time.Second
+
+	// DefaultMaxBackoff is the default upper limit of the backoff between retries.
+	DefaultMaxBackoff = 30 * time.Second
+
+	// DefaultMaxWait is the default upper limit of waiting for a rate limit to reset.
+	DefaultMaxWait = time.Minute
+)
+
+// secondaryRateLimitWait is how long to wait for a secondary rate limit without a Retry-After header.
+//
+// See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#handle-rate-limit-errors-appropriately
+const secondaryRateLimitWait = time.Minute
+
+// RateLimitError is returned when a rate limit is exceeded and waiting for the reset would take too long
+// (or the request ran out of retries).
+type RateLimitError struct {
+	// Reset is the time the rate limit resets.
+	Reset time.Time
+
+	// Secondary is true if a secondary rate limit was exceeded
+	// (eg. too many concurrent requests or too much content created in a short time).
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+
+	return fmt.Sprintf(
+		"GitHub API %s exceeded: try again after %s",
+		kind,
+		e.Reset.UTC().Format(time.RFC3339),
+	)
+}
+
+// Transport retries idempotent requests that failed with a network error or a server error
+// using exponential backoff with full jitter.
+//
+// Requests that hit a rate limit are retried (regardless of the method, since GitHub does not process them)
+// once the rate limit resets, according to the Retry-After or X-RateLimit-Reset headers.
+// If the reset is further than MaxWait, a [RateLimitError] is returned instead.
+// Server errors asking to retry later than MaxWait (see the Retry-After header) are returned as is.
+//
+// Requests with a body are only retried if the body can be rewound (ie. [http.Request.GetBody] is set).
+type Transport struct {
+	// Transport is the underlying transport.
+	//
+	// Defaults to [http.DefaultTransport].
+	Transport http.RoundTripper
+
+	// MaxRetries is the maximum number of retries (on top of the initial request).
+	//
+	// Defaults to [DefaultMaxRetries]. Set it to a negative number to disable retries.
+	MaxRetries int
+
+	// MinBackoff is the base of the exponential backoff.
+	//
+	// Defaults to [DefaultMinBackoff].
+	MinBackoff time.Duration
+
+	// MaxBackoff is the upper limit of the backoff.
+	//
+	// Defaults to [DefaultMaxBackoff].
+	MaxBackoff time.Duration
+
+	// MaxWait is the upper limit of waiting for a rate limit to reset
+	// (or for the Retry-After delay of a server error).
+	//
+	// Defaults to [DefaultMaxWait].
+	MaxWait time.Duration
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	maxRetries := t.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := transport.RoundTrip(attemptReq)
+
+		canRetry := attempt < maxRetries && rewindable
+
+		if err != nil {
+			if !canRetry || !isIdempotent(req.Method) || req.Context().Err() != nil {
+				return nil, err
+			}
+
+			err = sleep(req.Context(), t.backoff(attempt))
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		rateLimitErr, err := checkRateLimit(resp, time.Now())
+		if err != nil {
+			drain(resp)
+
+			return nil, err
+		}
+
+		var wait time.Duration
+
+		switch {
+		case rateLimitErr != nil:
+			wait = time.Until(rateLimitErr.Reset)
+
+			if !canRetry || wait > t.maxWait() {
+				drain(resp)
+
+				return nil, rateLimitErr
+			}
+
+		case isRetryableStatus(resp.StatusCode) && isIdempotent(req.Method) && canRetry:
+			wait = t.backoff(attempt)
+
+			// Honor Retry-After on server errors as well (eg. 503),
+			// but return the response instead of waiting longer than MaxWait
+			if retryAfter, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+				if retryAfter > t.maxWait() {
+					return resp, nil
+				}
+
+				wait = retryAfter
+			}
+
+		default:
+			return resp, nil
+		}
+
+		drain(resp)
+
+		err = sleep(req.Context(), wait)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *Transport) backoff(attempt int) time.Duration {
+	minBackoff := t.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+
+	maxBackoff := t.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	backoff := maxBackoff
+	if attempt < 32 && minBackoff<<attempt < maxBackoff {
+		backoff = minBackoff << attempt
+	}
+
+	// Full jitter
+	return rand.N(backoff + 1)
+}
+
+func (t *Transport) maxWait() time.Duration {
+	if t.MaxWait <= 0 {
+		return DefaultMaxWait
+	}
+
+	return t.MaxWait
+}
+
+// checkRateLimit returns a [RateLimitError] if the response indicates that a rate limit is exceeded.
+//
+// See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+func checkRateLimit(resp *http.Response, now time.Time) (*RateLimitError, error) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, nil
+	}
+
+	if retryAfter, ok := parseRetryAfter(resp.Header, now); ok {
+		return &RateLimitError{Reset: now.Add(retryAfter), Secondary: true}, nil
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset := now
+
+		if v, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			reset = time.Unix(v, 0)
+		}
+
+		return &RateLimitError{Reset: reset}, nil
+	}
+
+	// Secondary rate limits are not always indicated by headers, only by the error message.
+	// Peek into the body and restore it for the caller.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+	if strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return &RateLimitError{Reset: now.Add(secondaryRateLimitWait), Secondary: true}, nil
+	}
+
+	return nil, nil
+}
+
+// parseRetryAfter parses a Retry-After header (either in seconds or as an HTTP date).
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(v); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet,
+		http.MethodHead,
+		http.MethodOptions,
+		http.MethodTrace,
+		http.MethodPut,
+		http.MethodDelete:
+		return true
+
+	default:
+		return false
+	}
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// drain discards (a limited amount of) the response body so the connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,290 @@
+package retry_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sagikazarmark/octoslash/retry"
+)
+
+func TestTransport(t *testing.T) {
+	type response struct {
+		status int
+		header map[string]string
+		body   string
+	}
+
+	rateLimited := response{
+		status: http.StatusForbidden,
+		header: map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		method    string
+		responses []response
+		status    int
+		body      string
+		attempts  int32
+		rateLimit bool
+		secondary bool
+	}{
+		{
+			name:      "success",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusOK}},
+			status:    http.StatusOK,
+			attempts:  1,
+		},
+		{
+			name:   "server error",
+			method: http.MethodGet,
+			responses: []response{
+				{status: http.StatusBadGateway},
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusOK},
+			},
+			status:   http.StatusOK,
+			attempts: 3,
+		},
+		{
+			name:      "server error exhausts retries",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusInternalServerError}},
+			status:    http.StatusInternalServerError,
+			attempts:  4,
+		},
+		{
+			name:   "server error retry after",
+			method: http.MethodGet,
+			responses: []response{
+				{status: http.StatusServiceUnavailable, header: map[string]string{"Retry-After": "0"}},
+				{status: http.StatusOK},
+			},
+			status:   http.StatusOK,
+			attempts: 2,
+		},
+		{
+			name:   "server error retry after too far",
+			method: http.MethodGet,
+			responses: []response{
+				{status: http.StatusServiceUnavailable, header: map[string]string{"Retry-After": "3600"}},
+				{status: http.StatusOK},
+			},
+			status:   http.StatusServiceUnavailable,
+			attempts: 1,
+		},
+		{
+			name:      "server error not idempotent",
+			method:    http.MethodPost,
+			responses: []response{{status: http.StatusBadGateway}, {status: http.StatusOK}},
+			status:    http.StatusBadGateway,
+			attempts:  1,
+		},
+		{
+			name:      "client error",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusNotFound}, {status: http.StatusOK}},
+			status:    http.StatusNotFound,
+			attempts:  1,
+		},
+		{
+			name:   "retry after",
+			method: http.MethodPost,
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "0"}},
+				{status: http.StatusCreated},
+			},
+			status:   http.StatusCreated,
+			attempts: 2,
+		},
+		{
+			name:   "rate limit reset",
+			method: http.MethodGet,
+			responses: []response{
+				{
+					status: http.StatusForbidden,
+					header: map[string]string{
+						"X-RateLimit-Remaining": "0",
+						"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Unix()-1, 10),
+					},
+				},
+				{status: http.StatusOK},
+			},
+			status:   http.StatusOK,
+			attempts: 2,
+		},
+		{
+			name:      "rate limit reset too far",
+			method:    http.MethodGet,
+			responses: []response{rateLimited},
+			attempts:  1,
+			rateLimit: true,
+		},
+		{
+			name:   "forbidden",
+			method: http.MethodGet,
+			responses: []response{
+				{status: http.StatusForbidden, body: `{"message":"Resource not accessible"}`},
+				{status: http.StatusOK},
+			},
+			status:   http.StatusForbidden,
+			body:     `{"message":"Resource not accessible"}`,
+			attempts: 1,
+		},
+		{
+			name:   "secondary rate limit",
+			method: http.MethodPost,
+			responses: []response{
+				{
+					status: http.StatusForbidden,
+					body:   `{"message":"You have exceeded a secondary rate limit."}`,
+				},
+			},
+			attempts:  1,
+			rateLimit: true,
+			secondary: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			server := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					if r.Method == http.MethodPost && string(body) != "body" {
+						t.Errorf("expected the request body to be sent on every attempt, got %q", body)
+					}
+
+					i := int(attempts.Add(1)) - 1
+					response := testCase.responses[min(i, len(testCase.responses)-1)]
+
+					for key, value := range response.header {
+						w.Header().Set(key, value)
+					}
+
+					w.WriteHeader(response.status)
+					_, _ = io.WriteString(w, response.body)
+				}),
+			)
+			defer server.Close()
+
+			client := &http.Client{
+				Transport: &retry.Transport{
+					MinBackoff: time.Millisecond,
+					MaxBackoff: time.Millisecond,
+					MaxWait:    time.Second,
+				},
+			}
+
+			var body io.Reader
+			if testCase.method == http.MethodPost {
+				body = strings.NewReader("body")
+			}
+
+			req, err := http.NewRequestWithContext(t.Context(), testCase.method, server.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+
+			if got := attempts.Load(); got != testCase.attempts {
+				t.Errorf("expected %d attempts, got %d", testCase.attempts, got)
+			}
+
+			if testCase.rateLimit {
+				var rateLimitErr *retry.RateLimitError
+				if !errors.As(err, &rateLimitErr) {
+					t.Fatalf("expected a rate limit error, got %v", err)
+				}
+
+				if rateLimitErr.Reset.Before(time.Now()) {
+					t.Errorf("expected reset in the future, got %s", rateLimitErr.Reset)
+				}
+
+				if rateLimitErr.Secondary != testCase.secondary {
+					t.Errorf("expected secondary %t, got %t", testCase.secondary, rateLimitErr.Secondary)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != testCase.status {
+				t.Errorf("expected status %d, got %d", testCase.status, resp.StatusCode)
+			}
+
+			// The body should be intact after peeking for secondary rate limits
+			if respBody, _ := io.ReadAll(resp.Body); string(respBody) != testCase.body {
+				t.Errorf("expected body %q, got %q", testCase.body, respBody)
+			}
+		})
+	}
+}
+
+func TestTransport_BodyReadError(t *testing.T) {
+	body := &failingBody{}
+
+	transport := &retry.Transport{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Header:     http.Header{},
+				Body:       body,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transport.RoundTrip(req)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !body.closed {
+		t.Error("expected the response body to be closed")
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// failingBody is a response body that fails to be read.
+type failingBody struct {
+	closed bool
+}
+
+func (b *failingBody) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func (b *failingBody) Close() error {
+	b.closed = true
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package telemetry instruments command execution with OpenTelemetry.
+package telemetry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-github/v74/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sagikazarmark/octoslash/command"
+)
+
+// InstrumentationName is the name of the tracers and meters created by octoslash.
+const InstrumentationName = "github.com/sagikazarmark/octoslash"
+
+// ActionKey is the attribute key of the authorization action of a command.
+const ActionKey = attribute.Key("octoslash.action")
+
+// Middleware traces the execution of commands and counts them by action.
+//
+// The following counters are recorded:
+//
+//   - octoslash.commands.executed: commands that were authorized and executed
+//   - octoslash.commands.failed: commands that were executed, but failed
+//   - octoslash.commands.denied: commands that were not authorized
+func Middleware(
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) (command.Middleware, error) {
+	tracer := tracerProvider.Tracer(InstrumentationName)
+	meter := meterProvider.Meter(InstrumentationName)
+
+	executed, err := meter.Int64Counter(
+		"octoslash.commands.executed",
+		metric.WithDescription("Number of commands authorized and executed"),
+		metric.WithUnit("{command}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	failed, err := meter.Int64Counter(
+		"octoslash.commands.failed",
+		metric.WithDescription("Number of commands executed, but failed"),
+		metric.WithUnit("{command}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	denied, err := meter.Int64Counter(
+		"octoslash.commands.denied",
+		metric.WithDescription("Number of commands denied by authorization"),
+		metric.WithUnit("{command}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next command.Handler) command.Handler {
+		return command.HandlerFunc(func(ctx context.Context, inv command.Invocation) error {
+			ctx, span := tracer.Start(
+				ctx,
+				"command "+inv.Action,
+				trace.WithAttributes(ActionKey.String(inv.Action)),
+			)
+			defer span.End()
+
+			err := next.Handle(ctx, inv)
+
+			attrs := metric.WithAttributes(ActionKey.String(inv.Action))
+
+			switch {
+			case errors.Is(err, command.ErrUnauthorized):
+				denied.Add(ctx, 1, attrs)
+
+			case err != nil:
+				executed.Add(ctx, 1, attrs)
+				failed.Add(ctx, 1, attrs)
+
+			default:
+				executed.Add(ctx, 1, attrs)
+			}
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return err
+		})
+	}, nil
+}
+
+// Authorizer traces authorization requests.
+type Authorizer struct {
+	Authorizer     command.Authorizer
+	TracerProvider trace.TracerProvider
+}
+
+// Authorize implements [command.Authorizer].
+func (a Authorizer) Authorize(
+	ctx context.Context,
+	event github.IssueCommentEvent,
+	action string,
+) error {
+	ctx, span := a.TracerProvider.Tracer(InstrumentationName).Start(
+		ctx,
+		"authorize",
+		trace.WithAttributes(ActionKey.String(action)),
+	)
+	defer span.End()
+
+	err := a.Authorizer.Authorize(ctx, event, action)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.SetAttributes(attribute.Bool("octoslash.authorized", err == nil))
+
+	return err
+}
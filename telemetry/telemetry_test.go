@@ -0,0 +1,78 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/sagikazarmark/octoslash/command"
+	"github.com/sagikazarmark/octoslash/telemetry"
+)
+
+func TestMiddleware_Counters(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	middleware, err := telemetry.Middleware(noop.NewTracerProvider(), meterProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		nil,
+		nil,
+		errors.New("something went wrong"),
+		fmt.Errorf("%w: nope", command.ErrUnauthorized),
+	}
+
+	for _, err := range errs {
+		handler := command.Chain(command.HandlerFunc(func(context.Context, command.Invocation) error {
+			return err
+		}), middleware)
+
+		_ = handler.Handle(context.Background(), command.Invocation{Action: "label"})
+	}
+
+	var rm metricdata.ResourceMetrics
+
+	err = reader.Collect(context.Background(), &rm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int64{
+		"octoslash.commands.executed": 3,
+		"octoslash.commands.failed":   1,
+		"octoslash.commands.denied":   1,
+	}
+
+	actual := map[string]int64{}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("unexpected data type for %s: %T", m.Name, m.Data)
+			}
+
+			for _, dp := range sum.DataPoints {
+				if action, _ := dp.Attributes.Value(telemetry.ActionKey); action.AsString() != "label" {
+					t.Errorf("unexpected action attribute for %s: %q", m.Name, action.AsString())
+				}
+
+				actual[m.Name] += dp.Value
+			}
+		}
+	}
+
+	for name, value := range expected {
+		if actual[name] != value {
+			t.Errorf("expected %s to be %d, got %d", name, value, actual[name])
+		}
+	}
+}